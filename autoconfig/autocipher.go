@@ -31,6 +31,7 @@ import (
 	"reflect"
 
 	"github.com/stella-go/siu/config"
+	"github.com/stella-go/siu/inject"
 	"github.com/stella-go/siu/interfaces"
 )
 
@@ -59,6 +60,11 @@ func (p *AutoCipher) Condition() bool {
 	return ok1
 }
 
+// Conditions skips the factory when an interfaces.Cipher is registered or provided by the application.
+func (*AutoCipher) Conditions() []inject.Condition {
+	return []inject.Condition{inject.OnMissingBean(reflect.TypeOf((*interfaces.Cipher)(nil)).Elem())}
+}
+
 func (p *AutoCipher) OnStart() error {
 	skey := p.Conf.GetStringOr(CipherKeyKey, "")
 	shmacKey := p.Conf.GetStringOr(CipherHmacKeyKey, "")
@@ -72,6 +78,9 @@ func (p *AutoCipher) OnStart() error {
 	return nil
 }
 
+// Bootstrap starts AutoCipher before the beans are registered, the configuration is decrypted by its cipher.
+func (*AutoCipher) Bootstrap() {}
+
 func (p *AutoCipher) OnStop() error {
 	return nil
 }
@@ -163,8 +172,11 @@ func (p *CipherImpl) Decrypt(s string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	iv := enc[:16]
-	enc = enc[16:]
+	if len(enc) < 2*aes.BlockSize || len(enc)%aes.BlockSize != 0 {
+		return "", fmt.Errorf("malformed ciphertext of %d bytes, expected an IV and whole blocks of %d bytes", len(enc), aes.BlockSize)
+	}
+	iv := enc[:aes.BlockSize]
+	enc = enc[aes.BlockSize:]
 	dec := make([]byte, len(enc))
 	block, err := aes.NewCipher(p.Key)
 	if err != nil {
@@ -267,7 +279,13 @@ func pad(data []byte) []byte {
 	return append(data, bytes.Repeat([]byte{byte(tail)}, tail)...)
 }
 func unpad(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("unexpected padding")
+	}
 	tail := data[len(data)-1]
+	if tail == 0 || int(tail) > aes.BlockSize || int(tail) > len(data) {
+		return nil, fmt.Errorf("unexpected padding")
+	}
 	for i := len(data) - 1; i > len(data)-int(tail); i-- {
 		if data[i] != tail {
 			return nil, fmt.Errorf("unexpected padding")
@@ -0,0 +1,54 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoconfig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestCipherDecrypt(t *testing.T) {
+	c, err := NewCipherImpl("00112233445566778899aabbccddeeff", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(b []byte) string {
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	tests := []struct {
+		name      string
+		enc       string
+		plaintext string
+		err       bool
+	}{
+		{"round trip", c.Encrypt("s3cret"), "s3cret", false},
+		{"empty plaintext", c.Encrypt(""), "", false},
+		{"not base64", "!!!", "", true},
+		{"short", "YWJj", "", true},
+		{"iv only", encode(bytes.Repeat([]byte{1}, 16)), "", true},
+		{"misaligned", encode(bytes.Repeat([]byte{1}, 17)), "", true},
+		{"misaligned blocks", encode(bytes.Repeat([]byte{1}, 40)), "", true},
+		{"bad padding", encode(bytes.Repeat([]byte{1}, 32)), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext, err := c.Decrypt(tt.enc)
+			if (err != nil) != tt.err || plaintext != tt.plaintext {
+				t.Fatal(plaintext, err)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 
 	driver "github.com/go-sql-driver/mysql"
 	"github.com/stella-go/siu/config"
+	"github.com/stella-go/siu/inject"
 	"github.com/stella-go/siu/interfaces"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -38,9 +39,10 @@ const (
 )
 
 type AutoGorm struct {
-	Conf   config.TypedConfig `@siu:"name='environment',default='type'"`
-	Logger interfaces.Logger  `@siu:"name='logger',default='type'"`
-	dbs    map[string]*gorm.DB
+	Conf    config.TypedConfig `@siu:"name='environment',default='type'"`
+	Logger  interfaces.Logger  `@siu:"name='logger',default='type'"`
+	dbs     map[string]*gorm.DB
+	primary string
 }
 
 func (p *AutoGorm) Condition() bool {
@@ -53,11 +55,14 @@ func (p *AutoGorm) Condition() bool {
 	return ok1
 }
 
+// Conditions skips the factory when a *gorm.DB is registered or provided by the application.
+func (*AutoGorm) Conditions() []inject.Condition {
+	return []inject.Condition{inject.OnMissingBean(reflect.TypeOf((*gorm.DB)(nil)))}
+}
+
 func (p *AutoGorm) OnStart() error {
 	p.dbs = make(map[string]*gorm.DB)
 
-	datasources, _ := p.Conf.Get(GormDatasourceKey)
-
 	db, err := createGormDB(p.Logger, p.Conf, GormDatasourceKey)
 	if err != nil {
 		return err
@@ -66,12 +71,7 @@ func (p *AutoGorm) OnStart() error {
 		p.dbs[GormDatasourceKey] = db
 	}
 
-	datasourcesMap, ok := datasources.(map[string]interface{})
-	if !ok {
-		return nil
-	}
-	for datasource := range datasourcesMap {
-		name := GormDatasourceKey + "." + datasource
+	for _, name := range datasourceNames(p.Conf, GormDatasourceKey) {
 		db, err := createGormDB(p.Logger, p.Conf, name)
 		if err != nil {
 			return err
@@ -80,6 +80,11 @@ func (p *AutoGorm) OnStart() error {
 			p.dbs[name] = db
 		}
 	}
+	primary, err := primaryDatasource(p.Conf, GormDatasourceKey, p.dbs)
+	if err != nil {
+		return err
+	}
+	p.primary = primary
 	return nil
 }
 
@@ -94,6 +99,19 @@ func (p *AutoGorm) OnStop() error {
 	return nil
 }
 
+func (p *AutoGorm) Health(ctx context.Context) error {
+	for name, db := range p.dbs {
+		ins, err := db.DB()
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		if err := ins.PingContext(ctx); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
 func (*AutoGorm) Order() int {
 	return GormDatasourceOrder
 }
@@ -111,12 +129,10 @@ func (p *AutoGorm) Named() map[string]interface{} {
 }
 
 func (p *AutoGorm) Typed() map[reflect.Type]interface{} {
-	if len(p.dbs) == 1 {
-		for _, v := range p.dbs {
-			refType := reflect.TypeOf((*gorm.DB)(nil))
-			return map[reflect.Type]interface{}{
-				refType: v,
-			}
+	if db, ok := p.dbs[p.primary]; ok {
+		refType := reflect.TypeOf((*gorm.DB)(nil))
+		return map[reflect.Type]interface{}{
+			refType: db,
 		}
 	}
 	return nil
@@ -136,9 +152,9 @@ func createGormDB(logger interfaces.Logger, conf config.TypedConfig, prefix stri
 	}
 
 	collation := conf.GetStringOr(prefix+".collation", "utf8mb4_bin")
-	timeout := conf.GetIntOr(prefix+".timeout", 60000)
-	readTimeout := conf.GetIntOr(prefix+".readTimeout", 30000)
-	writeTimeout := conf.GetIntOr(prefix+".writeTimeout", 30000)
+	timeout := conf.GetDurationOr(prefix+".timeout", 60*time.Second)
+	readTimeout := conf.GetDurationOr(prefix+".readTimeout", 30*time.Second)
+	writeTimeout := conf.GetDurationOr(prefix+".writeTimeout", 30*time.Second)
 	loc := time.Local
 	if loca, ok := conf.GetString(prefix + ".loc"); ok {
 		if locl, err := time.LoadLocation(loca); err == nil {
@@ -146,24 +162,9 @@ func createGormDB(logger interfaces.Logger, conf config.TypedConfig, prefix stri
 		}
 	}
 
-	cparams, ok := conf.Get(prefix + ".params")
 	params := make(map[string]string)
-	if ok {
-		switch cparams := cparams.(type) {
-		case map[string]string:
-			params = cparams
-		case map[string]interface{}:
-			for k, v := range cparams {
-				params[k] = fmt.Sprintf("%s", v)
-			}
-		case map[interface{}]interface{}:
-			for k, v := range cparams {
-				key := fmt.Sprintf("%v", k)
-				value := fmt.Sprintf("%v", v)
-				params[key] = value
-			}
-		default:
-		}
+	for k, v := range conf.GetStringMapOr(prefix+".params", nil) {
+		params[k] = fmt.Sprintf("%v", v)
 	}
 	maxOpenConns := conf.GetIntOr(prefix+".maxOpenConns", 5)
 	maxIdleConns := conf.GetIntOr(prefix+".maxIdleConns", 1)
@@ -177,9 +178,9 @@ func createGormDB(logger interfaces.Logger, conf config.TypedConfig, prefix stri
 		ParseTime:            true,
 		Loc:                  loc,
 		Collation:            collation,
-		Timeout:              time.Duration(timeout) * time.Millisecond,
-		ReadTimeout:          time.Duration(readTimeout) * time.Millisecond,
-		WriteTimeout:         time.Duration(writeTimeout) * time.Millisecond,
+		Timeout:              timeout,
+		ReadTimeout:          readTimeout,
+		WriteTimeout:         writeTimeout,
 		Params:               params,
 		AllowNativePasswords: true,
 	}
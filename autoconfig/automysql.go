@@ -15,6 +15,7 @@
 package autoconfig
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
@@ -22,6 +23,7 @@ import (
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/stella-go/siu/config"
+	"github.com/stella-go/siu/inject"
 )
 
 const (
@@ -31,8 +33,9 @@ const (
 )
 
 type AutoMysql struct {
-	Conf config.TypedConfig `@siu:"name='environment',default='type'"`
-	dbs  map[string]*sql.DB
+	Conf    config.TypedConfig `@siu:"name='environment',default='type'"`
+	dbs     map[string]*sql.DB
+	primary string
 }
 
 func (p *AutoMysql) Condition() bool {
@@ -45,11 +48,14 @@ func (p *AutoMysql) Condition() bool {
 	return ok1
 }
 
+// Conditions skips the factory when a *sql.DB is registered or provided by the application.
+func (*AutoMysql) Conditions() []inject.Condition {
+	return []inject.Condition{inject.OnMissingBean(reflect.TypeOf((*sql.DB)(nil)))}
+}
+
 func (p *AutoMysql) OnStart() error {
 	p.dbs = make(map[string]*sql.DB)
 
-	datasources, _ := p.Conf.Get(MySQLDatasourceKey)
-
 	db, err := createDB(p.Conf, MySQLDatasourceKey)
 	if err != nil {
 		return err
@@ -58,12 +64,7 @@ func (p *AutoMysql) OnStart() error {
 		p.dbs[MySQLDatasourceKey] = db
 	}
 
-	datasourcesMap, ok := datasources.(map[string]interface{})
-	if !ok {
-		return nil
-	}
-	for datasource := range datasourcesMap {
-		name := MySQLDatasourceKey + "." + datasource
+	for _, name := range datasourceNames(p.Conf, MySQLDatasourceKey) {
 		db, err := createDB(p.Conf, name)
 		if err != nil {
 			return err
@@ -72,6 +73,11 @@ func (p *AutoMysql) OnStart() error {
 			p.dbs[name] = db
 		}
 	}
+	primary, err := primaryDatasource(p.Conf, MySQLDatasourceKey, p.dbs)
+	if err != nil {
+		return err
+	}
+	p.primary = primary
 	return nil
 }
 
@@ -84,6 +90,15 @@ func (p *AutoMysql) OnStop() error {
 	return nil
 }
 
+func (p *AutoMysql) Health(ctx context.Context) error {
+	for name, db := range p.dbs {
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
 func (*AutoMysql) Order() int {
 	return MySQLDatasourceOrder
 }
@@ -101,12 +116,10 @@ func (p *AutoMysql) Named() map[string]interface{} {
 }
 
 func (p *AutoMysql) Typed() map[reflect.Type]interface{} {
-	if len(p.dbs) == 1 {
-		for _, v := range p.dbs {
-			refType := reflect.TypeOf((*sql.DB)(nil))
-			return map[reflect.Type]interface{}{
-				refType: v,
-			}
+	if db, ok := p.dbs[p.primary]; ok {
+		refType := reflect.TypeOf((*sql.DB)(nil))
+		return map[reflect.Type]interface{}{
+			refType: db,
 		}
 	}
 	return nil
@@ -126,9 +139,9 @@ func createDB(conf config.TypedConfig, prefix string) (*sql.DB, error) {
 	}
 
 	collation := conf.GetStringOr(prefix+".collation", "utf8mb4_bin")
-	timeout := conf.GetIntOr(prefix+".timeout", 60000)
-	readTimeout := conf.GetIntOr(prefix+".readTimeout", 30000)
-	writeTimeout := conf.GetIntOr(prefix+".writeTimeout", 30000)
+	timeout := conf.GetDurationOr(prefix+".timeout", 60*time.Second)
+	readTimeout := conf.GetDurationOr(prefix+".readTimeout", 30*time.Second)
+	writeTimeout := conf.GetDurationOr(prefix+".writeTimeout", 30*time.Second)
 	loc := time.Local
 	if loca, ok := conf.GetString(prefix + ".loc"); ok {
 		if locl, err := time.LoadLocation(loca); err == nil {
@@ -136,24 +149,9 @@ func createDB(conf config.TypedConfig, prefix string) (*sql.DB, error) {
 		}
 	}
 
-	cparams, ok := conf.Get(prefix + ".params")
 	params := make(map[string]string)
-	if ok {
-		switch cparams := cparams.(type) {
-		case map[string]string:
-			params = cparams
-		case map[string]interface{}:
-			for k, v := range cparams {
-				params[k] = fmt.Sprintf("%s", v)
-			}
-		case map[interface{}]interface{}:
-			for k, v := range cparams {
-				key := fmt.Sprintf("%v", k)
-				value := fmt.Sprintf("%v", v)
-				params[key] = value
-			}
-		default:
-		}
+	for k, v := range conf.GetStringMapOr(prefix+".params", nil) {
+		params[k] = fmt.Sprintf("%v", v)
 	}
 	maxOpenConns := conf.GetIntOr(prefix+".maxOpenConns", 5)
 	maxIdleConns := conf.GetIntOr(prefix+".maxIdleConns", 1)
@@ -167,9 +165,9 @@ func createDB(conf config.TypedConfig, prefix string) (*sql.DB, error) {
 		ParseTime:            true,
 		Loc:                  loc,
 		Collation:            collation,
-		Timeout:              time.Duration(timeout) * time.Millisecond,
-		ReadTimeout:          time.Duration(readTimeout) * time.Millisecond,
-		WriteTimeout:         time.Duration(writeTimeout) * time.Millisecond,
+		Timeout:              timeout,
+		ReadTimeout:          readTimeout,
+		WriteTimeout:         writeTimeout,
 		Params:               params,
 		AllowNativePasswords: true,
 	}
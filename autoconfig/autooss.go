@@ -15,13 +15,16 @@
 package autoconfig
 
 import (
+	"context"
 	"reflect"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/stella-go/siu/config"
+	"github.com/stella-go/siu/inject"
 	"github.com/stella-go/siu/t"
 )
 
@@ -34,15 +37,25 @@ const (
 	OssRegionKey         = OssKey + ".region"
 	OssDdisableSSLKey    = OssKey + ".disable-ssl"
 	OssForcePathStyleKey = OssKey + ".force-path-style"
+	OssHealthBucketKey   = OssKey + ".health-bucket"
+	OssLazyKey           = OssKey + ".lazy"
 
 	OssOrder = 40
 )
 
 type AutoOss struct {
-	Conf   config.TypedConfig `@siu:"name='environment',default='type'"`
+	Conf config.TypedConfig `@siu:"name='environment',default='type'"`
+	// a lazy OSS is started by the first lookup while the health checks read the client
+	lock   sync.RWMutex
 	client *s3.S3
 }
 
+func (p *AutoOss) getClient() *s3.S3 {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.client
+}
+
 func (p *AutoOss) Condition() bool {
 	_, ok1 := p.Conf.Get(OssKey)
 	v, ok2 := p.Conf.GetBool(OssDisableKey)
@@ -53,6 +66,11 @@ func (p *AutoOss) Condition() bool {
 	return ok1
 }
 
+// Conditions skips the factory when a *s3.S3 is registered or provided by the application.
+func (*AutoOss) Conditions() []inject.Condition {
+	return []inject.Condition{inject.OnMissingBean(reflect.TypeOf((*s3.S3)(nil)))}
+}
+
 func (p *AutoOss) OnStart() error {
 	endpoint, ok := p.Conf.GetString(OssEndpointKey)
 	if !ok {
@@ -88,14 +106,39 @@ func (p *AutoOss) OnStart() error {
 	if err != nil {
 		return t.Error(err)
 	}
+	p.lock.Lock()
 	p.client = client
+	p.lock.Unlock()
 	return nil
 }
 
+// Lazy leaves the client to its first lookup, ListBuckets is not called at startup.
+func (p *AutoOss) Lazy() bool {
+	return p.Conf.GetBoolOr(OssLazyKey, false)
+}
+
+func (*AutoOss) Declared() ([]reflect.Type, map[string]reflect.Type) {
+	refType := reflect.TypeOf((*s3.S3)(nil))
+	return []reflect.Type{refType}, map[string]reflect.Type{OssKey: refType}
+}
+
 func (p *AutoOss) OnStop() error {
 	return nil
 }
 
+func (p *AutoOss) Health(ctx context.Context) error {
+	client := p.getClient()
+	if client == nil {
+		return nil
+	}
+	if bucket, ok := p.Conf.GetString(OssHealthBucketKey); ok && bucket != "" {
+		_, err := client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+		return err
+	}
+	_, err := client.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
+	return err
+}
+
 func (*AutoOss) Order() int {
 	return OssOrder
 }
@@ -106,13 +149,13 @@ func (*AutoOss) Name() string {
 
 func (p *AutoOss) Named() map[string]interface{} {
 	return map[string]interface{}{
-		OssKey: p.client,
+		OssKey: p.getClient(),
 	}
 }
 
 func (p *AutoOss) Typed() map[reflect.Type]interface{} {
 	refType := reflect.TypeOf((*s3.S3)(nil))
 	return map[reflect.Type]interface{}{
-		refType: p.client,
+		refType: p.getClient(),
 	}
 }
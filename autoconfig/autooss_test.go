@@ -0,0 +1,82 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoconfig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stella-go/siu/config"
+)
+
+// mapConfig is a configuration of flat keys.
+type mapConfig map[string]interface{}
+
+func (m mapConfig) Get(key string) (interface{}, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+func (m mapConfig) GetOr(key string, defaultValue interface{}) interface{} {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	return defaultValue
+}
+
+func TestOssLazyHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<ListAllMyBucketsResult><Buckets></Buckets></ListAllMyBucketsResult>`))
+	}))
+	defer server.Close()
+	oss := &AutoOss{Conf: &config.DecryptEnvironment{Config: mapConfig{
+		OssKey:         map[interface{}]interface{}{},
+		OssEndpointKey: server.URL,
+		OssAkKey:       "ak",
+		OssSkKey:       "sk",
+		OssLazyKey:     true,
+	}}}
+	if !oss.Lazy() {
+		t.Fatal("lazy")
+	}
+	if err := oss.Health(context.Background()); err != nil {
+		t.Fatal("healthy until started", err)
+	}
+	// the first lookup starts the factory while the health is checked
+	started := make(chan error)
+	go func() {
+		started <- oss.OnStart()
+	}()
+	for checking := true; checking; {
+		select {
+		case err := <-started:
+			if err != nil {
+				t.Fatal(err)
+			}
+			checking = false
+		default:
+			oss.Health(context.Background())
+		}
+	}
+	if err := oss.Health(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if client := oss.Named()[OssKey]; client == nil {
+		t.Fatal("client")
+	}
+}
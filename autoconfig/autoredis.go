@@ -23,6 +23,7 @@ import (
 
 	"github.com/go-redis/redis/v8"
 	"github.com/stella-go/siu/config"
+	"github.com/stella-go/siu/inject"
 )
 
 const (
@@ -55,6 +56,11 @@ func (p *AutoRedis) Condition() bool {
 	return ok1
 }
 
+// Conditions skips the factory when a redis.Cmdable is registered or provided by the application.
+func (*AutoRedis) Conditions() []inject.Condition {
+	return []inject.Condition{inject.OnMissingBean(reflect.TypeOf((*redis.Cmdable)(nil)).Elem())}
+}
+
 func (p *AutoRedis) OnStart() error {
 	addrStr, ok := p.Conf.GetString(RedisKey + ".addr")
 	if !ok {
@@ -91,6 +97,16 @@ func (p *AutoRedis) OnStop() error {
 	return nil
 }
 
+func (p *AutoRedis) Health(ctx context.Context) error {
+	if p.client != nil {
+		return p.client.Ping(ctx).Err()
+	}
+	if p.clusterClient != nil {
+		return p.clusterClient.Ping(ctx).Err()
+	}
+	return nil
+}
+
 func (*AutoRedis) Order() int {
 	return RedisDatasourceOrder
 }
@@ -137,18 +153,18 @@ func createRedis(conf config.TypedConfig, _ /*prefix*/ string) (*redis.Client, e
 	db := conf.GetIntOr(RedisDBKey, 0)
 	poolSize := conf.GetIntOr(RedisPoolSizeKey, 4)
 	minIdle := conf.GetIntOr(RedisMaxIdleKey, 1)
-	dialTimeout := conf.GetIntOr(RedisDialTimeoutKey, 5000)
-	readTimeout := conf.GetIntOr(RedisReadTimeoutKey, 5000)
-	writeTimeout := conf.GetIntOr(RedisWriteTimeoutKey, 5000)
+	dialTimeout := conf.GetDurationOr(RedisDialTimeoutKey, 5*time.Second)
+	readTimeout := conf.GetDurationOr(RedisReadTimeoutKey, 5*time.Second)
+	writeTimeout := conf.GetDurationOr(RedisWriteTimeoutKey, 5*time.Second)
 	client := redis.NewClient(&redis.Options{
 		Addr:         addr,
 		Password:     password,
 		DB:           db,
 		PoolSize:     poolSize,
 		MinIdleConns: minIdle,
-		DialTimeout:  time.Duration(dialTimeout) * time.Millisecond,
-		ReadTimeout:  time.Duration(readTimeout) * time.Millisecond,
-		WriteTimeout: time.Duration(writeTimeout) * time.Millisecond,
+		DialTimeout:  dialTimeout,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
 	})
 	ctx := context.Background()
 	if _, err := client.Ping(ctx).Result(); err != nil {
@@ -159,26 +175,25 @@ func createRedis(conf config.TypedConfig, _ /*prefix*/ string) (*redis.Client, e
 }
 
 func createClusterRedis(conf config.TypedConfig, _ /*prefix*/ string) (*redis.ClusterClient, error) {
-	addrStr, ok := conf.GetString(RedisAddrKey)
-	if !ok {
+	addrs, ok := conf.GetStringSlice(RedisAddrKey)
+	if !ok || len(addrs) == 0 {
 		return nil, fmt.Errorf("reids address can not be empty")
 	}
-	addrs := strings.Split(addrStr, ",")
 	password := conf.GetStringOr(RedisPasswordKey, "")
 	poolSize := conf.GetIntOr(RedisPoolSizeKey, 4)
 	minIdle := conf.GetIntOr(RedisMaxIdleKey, 1)
-	dialTimeout := conf.GetIntOr(RedisDialTimeoutKey, 5000)
-	readTimeout := conf.GetIntOr(RedisReadTimeoutKey, 5000)
-	writeTimeout := conf.GetIntOr(RedisWriteTimeoutKey, 5000)
+	dialTimeout := conf.GetDurationOr(RedisDialTimeoutKey, 5*time.Second)
+	readTimeout := conf.GetDurationOr(RedisReadTimeoutKey, 5*time.Second)
+	writeTimeout := conf.GetDurationOr(RedisWriteTimeoutKey, 5*time.Second)
 
 	clusterClient := redis.NewClusterClient(&redis.ClusterOptions{
 		Addrs:        addrs,
 		Password:     password,
 		PoolSize:     poolSize,
 		MinIdleConns: minIdle,
-		DialTimeout:  time.Duration(dialTimeout) * time.Millisecond,
-		ReadTimeout:  time.Duration(readTimeout) * time.Millisecond,
-		WriteTimeout: time.Duration(writeTimeout) * time.Millisecond,
+		DialTimeout:  dialTimeout,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
 	})
 	ctx := context.Background()
 	if _, err := clusterClient.Ping(ctx).Result(); err != nil {
@@ -15,13 +15,16 @@
 package autoconfig
 
 import (
+	"context"
 	"fmt"
+	"path"
 	"reflect"
-	"strings"
 	"time"
 
 	"github.com/go-zookeeper/zk"
 	"github.com/stella-go/siu/config"
+	"github.com/stella-go/siu/inject"
+	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -29,6 +32,7 @@ const (
 	ZookeeperDisableKey        = ZookeeperKey + ".disable"
 	ZookeeperServersKey        = ZookeeperKey + ".servers"
 	ZookeepersessionTimeoutKey = ZookeeperKey + ".sessionTimeout"
+	ZookeeperConfigRootKey     = ZookeeperKey + ".config.root"
 	ZookeeperOrder             = 10
 )
 
@@ -47,6 +51,14 @@ func (p *AutoZookeeper) Condition() bool {
 	return ok1
 }
 
+// Conditions skips the factory when a *zk.Conn is registered or provided by the application.
+func (*AutoZookeeper) Conditions() []inject.Condition {
+	return []inject.Condition{inject.OnMissingBean(reflect.TypeOf((*zk.Conn)(nil)))}
+}
+
+// OnStart connects to ZooKeeper and adds the node tree under zookeeper.config.root as a configuration source. It
+// runs with the other auto factories, after the configuration is validated and the registered beans are injected,
+// so the ZooKeeper values are only seen by the getters called afterwards and by the configuration watchers.
 func (p *AutoZookeeper) OnStart() error {
 	conn, err := createZookeeper(p.Conf, ZookeeperKey)
 	if err != nil {
@@ -55,6 +67,13 @@ func (p *AutoZookeeper) OnStart() error {
 	if conn != nil {
 		p.conn = conn
 	}
+	if root, ok := p.Conf.GetString(ZookeeperConfigRootKey); ok && p.conn != nil {
+		source, err := NewZookeeperSource(p.conn, root)
+		if err != nil {
+			return err
+		}
+		config.AddSource(source, config.RemoteSourceOrder)
+	}
 	return nil
 }
 
@@ -65,6 +84,16 @@ func (p *AutoZookeeper) OnStop() error {
 	return nil
 }
 
+func (p *AutoZookeeper) Health(_ context.Context) error {
+	if p.conn == nil {
+		return nil
+	}
+	if state := p.conn.State(); state != zk.StateHasSession {
+		return fmt.Errorf("zookeeper session state is %s", state)
+	}
+	return nil
+}
+
 func (*AutoZookeeper) Order() int {
 	return ZookeeperOrder
 }
@@ -87,14 +116,13 @@ func (p *AutoZookeeper) Typed() map[reflect.Type]interface{} {
 }
 
 func createZookeeper(Conf config.TypedConfig, _ /*prefix*/ string) (*zk.Conn, error) {
-	serversStr, ok := Conf.GetString(ZookeeperServersKey)
-	if !ok {
+	servers, ok := Conf.GetStringSlice(ZookeeperServersKey)
+	if !ok || len(servers) == 0 {
 		return nil, fmt.Errorf("zookeeper servers can not be empty")
 	}
-	servers := strings.Split(serversStr, ",")
-	timeout := Conf.GetIntOr(ZookeepersessionTimeoutKey, 60000)
+	timeout := Conf.GetDurationOr(ZookeepersessionTimeoutKey, 60*time.Second)
 
-	conn, event, err := zk.Connect(servers, time.Duration(timeout)*time.Millisecond)
+	conn, event, err := zk.Connect(servers, timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -109,3 +137,79 @@ outer:
 	}
 	return conn, nil
 }
+
+// ZookeeperSource reads the configuration from the node tree under root, the path of a leaf node is its key,
+// e.g. /config/app/server/port for server.port. A root node without children holds a YAML document.
+type ZookeeperSource struct {
+	config.TreeSource
+	conn *zk.Conn
+	root string
+}
+
+func NewZookeeperSource(conn *zk.Conn, root string) (*ZookeeperSource, error) {
+	tree, err := readZookeeperTree(conn, root)
+	if err != nil {
+		return nil, err
+	}
+	return &ZookeeperSource{TreeSource: config.NewMapSource("zookeeper:"+root, tree), conn: conn, root: root}, nil
+}
+
+func (p *ZookeeperSource) Reload() (config.ConfigSource, error) {
+	source, err := NewZookeeperSource(p.conn, p.root)
+	if err != nil {
+		return nil, err
+	}
+	if reflect.DeepEqual(source.Tree(), p.Tree()) {
+		return nil, nil
+	}
+	return source, nil
+}
+
+func readZookeeperTree(conn *zk.Conn, root string) (map[interface{}]interface{}, error) {
+	children, _, err := conn.Children(root)
+	if err != nil {
+		return nil, err
+	}
+	tree := make(map[interface{}]interface{})
+	if len(children) == 0 {
+		data, _, err := conn.Get(root)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, err
+		}
+		return tree, nil
+	}
+	for _, child := range children {
+		value, err := readZookeeperNode(conn, path.Join(root, child))
+		if err != nil {
+			return nil, err
+		}
+		tree[child] = value
+	}
+	return tree, nil
+}
+
+func readZookeeperNode(conn *zk.Conn, node string) (interface{}, error) {
+	children, _, err := conn.Children(node)
+	if err != nil {
+		return nil, err
+	}
+	if len(children) == 0 {
+		data, _, err := conn.Get(node)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	m := make(map[interface{}]interface{})
+	for _, child := range children {
+		value, err := readZookeeperNode(conn, path.Join(node, child))
+		if err != nil {
+			return nil, err
+		}
+		m[child] = value
+	}
+	return m, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/stella-go/siu/config"
+)
+
+// datasourceNames returns the prefixes of the named datasources configured under the key, e.g. gorm.reporting,
+// params holds the connection parameters of the default datasource.
+func datasourceNames(conf config.TypedConfig, key string) []string {
+	datasources, ok := conf.GetStringMap(key)
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(datasources))
+	for datasource, v := range datasources {
+		if datasource == "params" {
+			continue
+		}
+		if _, ok := v.(map[interface{}]interface{}); !ok {
+			if _, ok := v.(map[string]interface{}); !ok {
+				continue
+			}
+		}
+		names = append(names, key+"."+datasource)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// primaryDatasource returns the prefix of the datasource injected by type: the only datasource, or the one
+// configured with `primary: true`. It returns an empty prefix when none of several datasources is primary.
+func primaryDatasource[T any](conf config.TypedConfig, key string, dbs map[string]T) (string, error) {
+	primaries := make([]string, 0)
+	for name := range dbs {
+		if conf.GetBoolOr(name+".primary", false) {
+			primaries = append(primaries, name)
+		}
+	}
+	sort.Strings(primaries)
+	if len(primaries) > 1 {
+		return "", fmt.Errorf("%s datasources %s can not be all primary", key, strings.Join(primaries, ", "))
+	}
+	if len(primaries) == 1 {
+		return primaries[0], nil
+	}
+	if len(dbs) == 1 {
+		for name := range dbs {
+			return name, nil
+		}
+	}
+	return "", nil
+}
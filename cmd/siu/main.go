@@ -0,0 +1,157 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command siu encrypts, decrypts and generates the keys of the configuration secrets.
+//
+// The cipher keys are read like the configuration of an application: from the command line flags, e.g.
+// --cipher.key=<hex>, the STELLA_ environment variables and the configuration files, --config=a.yml,b.yml
+// reads the files before application.yml.
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/stella-go/logger"
+	"github.com/stella-go/siu/autoconfig"
+	"github.com/stella-go/siu/common"
+	"github.com/stella-go/siu/config"
+)
+
+const usage = `Usage:
+  siu cipher encrypt [--cipher.key=<hex>] [--config=<files>] [plaintext]
+  siu cipher decrypt [--cipher.key=<hex>] [--config=<files>] [ENC(...)]
+  siu cipher genkey [--bytes=32]
+  siu cipher genrsa [--bits=2048]
+
+The plaintext and the encrypted value are read from stdin when they are not given.
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "siu: %v\n\n%s", err, usage)
+		os.Exit(2)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	common.SetLevel(logger.WarnLevel)
+	positional := make([]string, 0)
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--config=") {
+			os.Setenv("STELLA_CONFIG_FILES", strings.TrimPrefix(arg, "--config="))
+		} else if !strings.HasPrefix(arg, "-") {
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) < 2 || positional[0] != "cipher" {
+		return fmt.Errorf("unknown command: %s", strings.Join(positional, " "))
+	}
+	conf := &config.DecryptEnvironment{Config: &flagConfig{flags: config.NewFlagSource(args)}}
+	switch command := positional[1]; command {
+	case "encrypt", "decrypt":
+		cipher, err := newCipher(conf)
+		if err != nil {
+			return err
+		}
+		value, err := input(positional[2:], stdin)
+		if err != nil {
+			return err
+		}
+		if command == "encrypt" {
+			fmt.Fprintf(stdout, "ENC(%s)\n", cipher.Encrypt(value))
+			return nil
+		}
+		if strings.HasPrefix(value, "ENC(") && strings.HasSuffix(value, ")") {
+			value = value[len("ENC(") : len(value)-1]
+		}
+		plaintext, err := cipher.Decrypt(value)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %v", value, err)
+		}
+		fmt.Fprintln(stdout, plaintext)
+		return nil
+	case "genkey":
+		n := conf.GetIntOr("bytes", 32)
+		key := make([]byte, n)
+		hmacKey := make([]byte, n)
+		if _, err := rand.Read(key); err != nil {
+			return err
+		}
+		if _, err := rand.Read(hmacKey); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "cipher:\n  key: %s\n  hmac-key: %s\n", hex.EncodeToString(key), hex.EncodeToString(hmacKey))
+		return nil
+	case "genrsa":
+		privateKey, publicKey := (&autoconfig.CipherImpl{}).GenRsaKeyPair(conf.GetIntOr("bits", 2048))
+		fmt.Fprintf(stdout, "cipher:\n  public-key: |\n%s  private-key: |\n%s", indent(publicKey), indent(privateKey))
+		return nil
+	default:
+		return fmt.Errorf("unknown command: cipher %s", command)
+	}
+}
+
+// flagConfig reads the flags passed to run before the configuration of the environment.
+type flagConfig struct {
+	flags config.ConfigSource
+}
+
+func (p *flagConfig) Get(key string) (interface{}, bool) {
+	if value, ok := p.flags.Get(key); ok {
+		return value, true
+	}
+	return (&config.ConfigurationEnvironment{}).Get(key)
+}
+
+func (p *flagConfig) GetOr(key string, defaultValue interface{}) interface{} {
+	if value, ok := p.Get(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func newCipher(conf config.TypedConfig) (*autoconfig.CipherImpl, error) {
+	key := conf.GetStringOr(autoconfig.CipherKeyKey, "")
+	if key == "" {
+		return nil, fmt.Errorf("%s is not configured, pass --%s=<hex> or --config=<files>", autoconfig.CipherKeyKey, autoconfig.CipherKeyKey)
+	}
+	return autoconfig.NewCipherImpl(key, conf.GetStringOr(autoconfig.CipherHmacKeyKey, ""), conf.GetStringOr(autoconfig.CipherPublicKeyKey, ""), conf.GetStringOr(autoconfig.CipherPrivateKeyKey, ""))
+}
+
+// input returns the value argument, or the content of stdin without the trailing new line.
+func input(args []string, stdin io.Reader) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	bts, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(bts), "\r\n"), nil
+}
+
+func indent(pem string) string {
+	b := &strings.Builder{}
+	for _, line := range strings.SplitAfter(pem, "\n") {
+		if line != "" {
+			b.WriteString("    " + line)
+		}
+	}
+	return b.String()
+}
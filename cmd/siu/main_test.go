@@ -0,0 +1,89 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var hexPattern = regexp.MustCompile(`[0-9a-f]{32,}`)
+
+func TestRun(t *testing.T) {
+	t.Setenv("STELLA_CIPHER_KEY", "00112233445566778899aabbccddeeff")
+	t.Setenv("STELLA_CIPHER_HMAC_KEY", "ffeeddccbbaa99887766554433221100")
+
+	encrypted := &bytes.Buffer{}
+	if err := run([]string{"cipher", "encrypt", "s3cret"}, strings.NewReader(""), encrypted); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encrypted.String(), "ENC(") {
+		t.Fatal(encrypted.String())
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		output string
+		err    bool
+	}{
+		{"decrypt argument", []string{"cipher", "decrypt", strings.TrimSpace(encrypted.String())}, "", "s3cret\n", false},
+		{"decrypt stdin", []string{"cipher", "decrypt"}, encrypted.String(), "s3cret\n", false},
+		{"encrypt stdin", []string{"cipher", "encrypt"}, "s3cret\n", "ENC(", false},
+		{"decrypt invalid", []string{"cipher", "decrypt", "ENC(invalid)"}, "", "", true},
+		{"decrypt short", []string{"cipher", "decrypt", "ENC(YWJj)"}, "", "", true},
+		{"decrypt misaligned", []string{"cipher", "decrypt", "ENC(" + base64.StdEncoding.EncodeToString(make([]byte, 17)) + ")"}, "", "", true},
+		{"decrypt misaligned stdin", []string{"cipher", "decrypt"}, base64.StdEncoding.EncodeToString(make([]byte, 40)), "", true},
+		{"genkey", []string{"cipher", "genkey"}, "", "cipher:\n  key: " + strings.Repeat("x", 64) + "\n", false},
+		{"genkey bytes", []string{"cipher", "genkey", "--bytes=16"}, "", "cipher:\n  key: " + strings.Repeat("x", 32) + "\n", false},
+		{"genrsa", []string{"cipher", "genrsa", "--bits=1024"}, "", "cipher:\n  public-key: |\n    -----BEGIN", false},
+		{"no command", []string{}, "", "", true},
+		{"unknown group", []string{"key", "genkey"}, "", "", true},
+		{"unknown command", []string{"cipher", "sign"}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			err := run(tt.args, strings.NewReader(tt.stdin), stdout)
+			if (err != nil) != tt.err {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(hexPattern.ReplaceAllStringFunc(stdout.String(), func(h string) string {
+				return strings.Repeat("x", len(h))
+			}), tt.output) {
+				t.Fatal(stdout.String())
+			}
+		})
+	}
+
+	t.Setenv("STELLA_CIPHER_KEY", "")
+	if err := run([]string{"cipher", "encrypt", "s3cret"}, strings.NewReader(""), &bytes.Buffer{}); err == nil {
+		t.Fatal("cipher.key is not configured")
+	}
+	stdout := &bytes.Buffer{}
+	if err := run([]string{"cipher", "decrypt", "--cipher.key=00112233445566778899aabbccddeeff", strings.TrimSpace(encrypted.String())}, strings.NewReader(""), stdout); err != nil || stdout.String() != "s3cret\n" {
+		t.Fatal("cipher.key flag", stdout.String(), err)
+	}
+}
+
+func TestIndent(t *testing.T) {
+	if s := indent("a\nb\n"); s != "    a\n    b\n" {
+		t.Fatal(s)
+	}
+}
@@ -17,18 +17,22 @@ package common
 import (
 	"fmt"
 	"log"
+	"sync/atomic"
 
 	"github.com/stella-go/logger"
 )
 
 var (
-	level logger.Level = logger.InfoLevel
-	tag   string       = "[SIU]"
+	level int64  = int64(logger.InfoLevel)
+	tag   string = "[SIU]"
 )
 
-// concurrency unsafe, used only during siu context initialization
 func SetLevel(lv logger.Level) {
-	level = lv
+	atomic.StoreInt64(&level, int64(lv))
+}
+
+func GetLevel() logger.Level {
+	return logger.Level(atomic.LoadInt64(&level))
 }
 
 // concurrency unsafe, used only during siu context initialization
@@ -37,7 +41,7 @@ func SetTag(t string) {
 }
 
 func DEBUG(format string, v ...interface{}) {
-	if level <= logger.DebugLevel {
+	if GetLevel() <= logger.DebugLevel {
 		if len(v) > 0 {
 			if _, ok := v[len(v)-1].(error); ok {
 				format += " %v"
@@ -49,7 +53,7 @@ func DEBUG(format string, v ...interface{}) {
 }
 
 func INFO(format string, v ...interface{}) {
-	if level <= logger.InfoLevel {
+	if GetLevel() <= logger.InfoLevel {
 		if len(v) > 0 {
 			if _, ok := v[len(v)-1].(error); ok {
 				format += " %v"
@@ -61,7 +65,7 @@ func INFO(format string, v ...interface{}) {
 }
 
 func WARN(format string, v ...interface{}) {
-	if level <= logger.WarnLevel {
+	if GetLevel() <= logger.WarnLevel {
 		if len(v) > 0 {
 			if _, ok := v[len(v)-1].(error); ok {
 				format += " %v"
@@ -73,7 +77,7 @@ func WARN(format string, v ...interface{}) {
 }
 
 func ERROR(format string, v ...interface{}) {
-	if level <= logger.ErrorLevel {
+	if GetLevel() <= logger.ErrorLevel {
 		if len(v) > 0 {
 			if _, ok := v[len(v)-1].(error); ok {
 				format += " %v"
@@ -0,0 +1,246 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// BindTag is the struct tag used by Bind, e.g. `config:"name='dial-timeout',default='5s'"`, `config:"-"` skips the field.
+const BindTag = "config"
+
+// Bind maps the configuration subtree under prefix onto out, which must be a pointer to a struct.
+// Every leaf is looked up with its full key, so the STELLA_ environment variables override the files.
+// The rules of the config tag are checked, all the invalid keys are returned as a *ValidationError.
+func Bind(prefix string, out interface{}) error {
+	return BindFrom(env, prefix, out)
+}
+
+// BindFrom is like Bind but reads the configuration from c.
+func BindFrom(c Config, prefix string, out interface{}) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind %s: out must be a non-nil pointer to struct, got %T", prefix, out)
+	}
+	b := &binder{c: c, errs: &ValidationError{}}
+	b.bindStruct(prefix, v.Elem())
+	return b.errs.Err()
+}
+
+// mapConfig reads a raw configuration map, it is used to convert map values into structs.
+type mapConfig struct {
+	m map[interface{}]interface{}
+}
+
+func (p *mapConfig) Get(key string) (interface{}, bool) {
+	return get(p.m, key)
+}
+
+func (p *mapConfig) GetOr(key string, defaultValue interface{}) interface{} {
+	if value, ok := p.Get(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+type binder struct {
+	c    Config
+	errs *ValidationError
+}
+
+func (b *binder) fail(key string, value interface{}, fromDefault bool, message string) {
+	source := ""
+	if fromDefault {
+		source = "default"
+	} else if sc, ok := b.c.(SourceConfig); ok {
+		source, _ = sc.Source(key)
+	}
+	b.errs.Errors = append(b.errs.Errors, &FieldError{Key: key, Value: value, Source: source, Message: message})
+}
+
+func (b *binder) bindStruct(prefix string, v reflect.Value) {
+	typ := v.Type()
+	groups := make(map[string][]string)
+	satisfied := make(map[string]bool)
+	order := make([]string, 0)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldValue := v.Field(i)
+		if !fieldValue.CanSet() {
+			continue
+		}
+		tag, ok := field.Tag.Lookup(BindTag)
+		if ok && tag == "-" {
+			continue
+		}
+		tagMap, err := ParseTag(tag)
+		if err != nil {
+			b.fail(joinKey(prefix, field.Name), nil, false, err.Error())
+			continue
+		}
+		_, hasName := tagMap["name"]
+		if field.Anonymous && !hasName && field.Type.Kind() == reflect.Struct {
+			b.bindStruct(prefix, fieldValue)
+			continue
+		}
+		key := lookupKey(b.c, prefix, fieldNames(field, tagMap))
+		errs := len(b.errs.Errors)
+		var raw interface{}
+		var present, fromDefault bool
+		encrypted := false
+		if value, ok := b.c.Get(key); ok {
+			if s, ok := value.(string); ok && IsEncrypted(s) {
+				// the rules are checked once the value is decrypted, e.g. when the application validates its
+				// configuration before the cipher is started
+				encrypted, raw, present = true, s, true
+				if fieldValue.Kind() == reflect.String {
+					fieldValue.SetString(s)
+				}
+			}
+		}
+		if !encrypted {
+			raw, present, fromDefault = b.bindValue(key, fieldValue, tagMap)
+		}
+		if group, ok := tagMap["one-of"]; ok {
+			if _, ok := groups[group]; !ok {
+				order = append(order, group)
+			}
+			groups[group] = append(groups[group], key)
+			satisfied[group] = satisfied[group] || present
+		}
+		if len(b.errs.Errors) > errs || encrypted {
+			continue
+		}
+		if message := validate(tagMap, present, fieldValue); message != "" {
+			b.fail(key, raw, fromDefault, message)
+		}
+	}
+	for _, group := range order {
+		if !satisfied[group] {
+			b.fail(strings.Join(groups[group], "|"), nil, false, fmt.Sprintf("one of %s is required", strings.Join(groups[group], ", ")))
+		}
+	}
+}
+
+// bindValue binds the key to v, it returns the configured value and whether the key is configured.
+func (b *binder) bindValue(key string, v reflect.Value, tagMap map[string]string) (interface{}, bool, bool) {
+	raw, ok := b.c.Get(key)
+	typ := v.Type()
+	switch {
+	case typ.Kind() == reflect.Struct && typ != durationType:
+		if _, isMap := raw.(map[interface{}]interface{}); ok && !isMap {
+			b.fail(key, raw, false, fmt.Sprintf("can not convert %v to %s", raw, typ))
+			return raw, ok, false
+		}
+		b.bindStruct(key, v)
+		return nil, ok, false
+	case typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct && typ.Elem() != durationType:
+		if !ok {
+			return nil, false, false
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(typ.Elem()))
+		}
+		return b.bindValue(key, v.Elem(), tagMap)
+	case typ.Kind() == reflect.Map:
+		m, isMap := raw.(map[interface{}]interface{})
+		if !isMap {
+			break
+		}
+		// bind the elements one by one so that nested structs get their defaults and environment overrides
+		mv := reflect.MakeMapWithSize(typ, len(m))
+		for k := range m {
+			elemKey := fmt.Sprintf("%s.%v", key, k)
+			mk, err := Convert(k, typ.Key())
+			if err != nil {
+				b.fail(elemKey, k, false, err.Error())
+				continue
+			}
+			elem := reflect.New(typ.Elem()).Elem()
+			b.bindValue(elemKey, elem, nil)
+			mv.SetMapIndex(mk, elem)
+		}
+		v.Set(mv)
+		return nil, true, false
+	}
+	fromDefault := false
+	if !ok {
+		defaultValue, hasDefault := tagMap["default"]
+		if !hasDefault {
+			return nil, false, false
+		}
+		raw = defaultValue
+		fromDefault = true
+	}
+	value, err := Convert(raw, typ)
+	if err != nil {
+		b.fail(key, raw, fromDefault, err.Error())
+		return raw, ok, fromDefault
+	}
+	v.Set(value)
+	return raw, ok, fromDefault
+}
+
+// lookupKey returns the first candidate key present in the configuration, or the first candidate.
+func lookupKey(c Config, prefix string, names []string) string {
+	for _, name := range names {
+		key := joinKey(prefix, name)
+		if _, ok := c.Get(key); ok {
+			return key
+		}
+	}
+	return joinKey(prefix, names[0])
+}
+
+func joinKey(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// fieldNames returns the candidate keys of a field: the tag name, or the lowerCamel, kebab-case and exact field name.
+func fieldNames(field reflect.StructField, tagMap map[string]string) []string {
+	if name, ok := tagMap["name"]; ok && name != "" {
+		return []string{name}
+	}
+	words := splitWords(field.Name)
+	camel := strings.ToLower(words[0])
+	for _, w := range words[1:] {
+		camel += strings.ToUpper(w[:1]) + strings.ToLower(w[1:])
+	}
+	kebab := strings.ToLower(strings.Join(words, "-"))
+	return []string{camel, kebab, field.Name}
+}
+
+// splitWords splits a Go identifier into words, e.g. MaxURLLength -> Max URL Length.
+func splitWords(name string) []string {
+	runes := []rune(name)
+	words := make([]string, 0)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		if !unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
@@ -0,0 +1,89 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var encryptedPattern = regexp.MustCompile(`ENC\(([^()]*)\)`)
+
+// IsEncrypted reports whether the value contains an ENC(...) value.
+func IsEncrypted(value string) bool {
+	return encryptedPattern.MatchString(value)
+}
+
+// decryptValue replaces every ENC(...) of the strings of value with the plaintext, maps and slices are copied.
+// It reports whether anything was decrypted.
+func decryptValue(value interface{}, c Cipher) (interface{}, bool, error) {
+	switch value := value.(type) {
+	case string:
+		if !IsEncrypted(value) {
+			return value, false, nil
+		}
+		if c == nil {
+			return nil, false, fmt.Errorf("no cipher is available to decrypt %s", value)
+		}
+		var err error
+		decrypted := encryptedPattern.ReplaceAllStringFunc(value, func(s string) string {
+			if err != nil {
+				return s
+			}
+			var plaintext string
+			plaintext, err = decrypt(c, encryptedPattern.FindStringSubmatch(s)[1])
+			return plaintext
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		return decrypted, true, nil
+	case []interface{}:
+		r := make([]interface{}, len(value))
+		changed := false
+		for i, v := range value {
+			d, ok, err := decryptValue(v, c)
+			if err != nil {
+				return nil, false, err
+			}
+			r[i] = d
+			changed = changed || ok
+		}
+		return r, changed, nil
+	case map[interface{}]interface{}:
+		r := make(map[interface{}]interface{}, len(value))
+		changed := false
+		for k, v := range value {
+			d, ok, err := decryptValue(v, c)
+			if err != nil {
+				return nil, false, err
+			}
+			r[k] = d
+			changed = changed || ok
+		}
+		return r, changed, nil
+	}
+	return value, false, nil
+}
+
+// decrypt calls the cipher, a cipher panicking on a malformed value returns an error instead.
+func decrypt(c Cipher, enc string) (plaintext string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to decrypt %s: %v", enc, r)
+		}
+	}()
+	return c.Decrypt(enc)
+}
@@ -15,120 +15,261 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/stella-go/siu/common"
-	"gopkg.in/yaml.v2"
 )
 
-var null struct{}
+const (
+	ProfilesActiveKey = "profiles.active"
+	ProfilesEnvKey    = "STELLA_PROFILES"
+)
 
 var (
 	once         = &sync.Once{}
 	rwLock       = &sync.RWMutex{}
 	defaultFiles = []string{"application.yml", "config/application.yml"}
-	env          = &environment{files: make([]string, 0), configs: make([]map[interface{}]interface{}, 0)}
+	env          = newEnvironment()
 )
 
+type sourceEntry struct {
+	source ConfigSource
+	order  int
+}
+
 type environment struct {
-	files   []string
-	configs []map[interface{}]interface{}
+	typedConfig
+	sources  []*sourceEntry
+	profiles []string
+	origins  *sync.Map
+}
+
+// typedConfig implements the typed getters of TypedConfig on top of Get.
+type typedConfig struct {
+	getter
+}
+
+type getter interface {
+	Get(key string) (interface{}, bool)
+}
+
+func newEnvironment() *environment {
+	p := &environment{sources: make([]*sourceEntry, 0), origins: &sync.Map{}}
+	p.typedConfig = typedConfig{p}
+	p.addSource(&envSource{}, EnvSourceOrder)
+	return p
+}
+
+// addSource inserts the source after the sources of lower or equal order, the caller holds the write lock.
+func (p *environment) addSource(source ConfigSource, order int) {
+	i := len(p.sources)
+	for i > 0 && p.sources[i-1].order > order {
+		i--
+	}
+	p.sources = append(p.sources, nil)
+	copy(p.sources[i+1:], p.sources[i:])
+	p.sources[i] = &sourceEntry{source: source, order: order}
+}
+
+func (p *environment) hasSource(name string) bool {
+	for _, e := range p.sources {
+		if e.source.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *environment) sourceList() []ConfigSource {
+	sources := make([]ConfigSource, 0, len(p.sources))
+	for _, e := range p.sources {
+		sources = append(sources, e.source)
+	}
+	return sources
 }
 
 func tryLoadConfig(files ...string) {
+	rwLock.Lock()
+	env.addSource(NewFlagSource(os.Args[1:]), FlagSourceOrder)
+	rwLock.Unlock()
+
 	envConfigFiles := os.Getenv("STELLA_CONFIG_FILES")
 	if envConfigFiles != "" {
 		envFiles := strings.Split(envConfigFiles, ",")
 		LoadConfig(envFiles...)
 	}
 
-	names := make([]string, 0)
-	maps := make([]map[interface{}]interface{}, 0)
-	for _, file := range files {
-		m := make(map[interface{}]interface{})
-		bts, err := os.ReadFile(file)
-		if err != nil {
-			continue
+	sources := tryReadConfig(files...)
+
+	// the profile files take precedence over the base files, the last active profile wins
+	rwLock.Lock()
+	defer rwLock.Unlock()
+	profiles := activeProfiles(append(env.sourceList(), sources...))
+	profileFiles := make([]string, 0)
+	for i := len(profiles) - 1; i >= 0; i-- {
+		for _, file := range files {
+			profileFiles = append(profileFiles, profileFile(file, profiles[i]))
 		}
-		err = yaml.Unmarshal(bts, &m)
+	}
+	profileSources := tryReadConfig(profileFiles...)
+	if os.Getenv(ProfilesEnvKey) != "" {
+		profileSources = append([]ConfigSource{NewMapSource(ProfilesEnvKey, map[interface{}]interface{}{"profiles": map[interface{}]interface{}{"active": strings.Join(profiles, ",")}})}, profileSources...)
+	}
+	env.profiles = profiles
+	if len(profiles) > 0 {
+		common.INFO("Active profiles: %s", strings.Join(profiles, ","))
+	}
+
+	for _, source := range append(profileSources, sources...) {
+		env.addSource(source, FileSourceOrder)
+	}
+}
+
+func tryReadConfig(files ...string) []ConfigSource {
+	sources := make([]ConfigSource, 0)
+	for _, file := range files {
+		source, err := NewFileSource(file)
 		if err != nil {
 			continue
 		}
-		names = append(names, file)
-		maps = append(maps, m)
+		sources = append(sources, source)
 		common.INFO("Load configuration file: %s success", file)
 	}
-	env.files = append(env.files, names...)
-	env.configs = append(env.configs, maps...)
+	return sources
+}
+
+// activeProfiles reads STELLA_PROFILES, or profiles.active of the sources.
+func activeProfiles(sources []ConfigSource) []string {
+	var active interface{} = os.Getenv(ProfilesEnvKey)
+	if active == "" {
+		for _, source := range sources {
+			if v, ok := source.Get(ProfilesActiveKey); ok {
+				active = v
+				break
+			}
+		}
+	}
+	profiles := make([]string, 0)
+	switch active := active.(type) {
+	case string:
+		for _, p := range strings.Split(active, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				profiles = append(profiles, p)
+			}
+		}
+	case []interface{}:
+		for _, p := range active {
+			profiles = append(profiles, strings.TrimSpace(fmt.Sprintf("%v", p)))
+		}
+	}
+	return profiles
+}
+
+// profileFile returns the file name of the profile, config/application.yml -> config/application-dev.yml
+func profileFile(file string, profile string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "-" + profile + ext
+}
+
+// Profiles returns the active profiles.
+func Profiles() []string {
+	once.Do(func() {
+		tryLoadConfig(defaultFiles...)
+	})
+	rwLock.RLock()
+	defer rwLock.RUnlock()
+	return append([]string{}, env.profiles...)
 }
 
 func LoadConfig(files ...string) {
 	rwLock.Lock()
 	defer rwLock.Unlock()
-	alreadyDone := make(map[string]struct{})
-	for _, f := range env.files {
-		alreadyDone[f] = null
-	}
-	names := make([]string, 0)
-	maps := make([]map[interface{}]interface{}, 0)
 	for _, file := range files {
-		if _, done := alreadyDone[file]; done {
+		if env.hasSource(file) {
 			common.WARN("Already load configuration file: %s", file)
 			continue
 		}
-		m := make(map[interface{}]interface{})
-		bts, err := os.ReadFile(file)
+		source, err := NewFileSource(file)
 		if err != nil {
-			common.ERROR("Failed to read configuration file: %s, with error", file, err)
+			common.ERROR("Failed to load configuration file: %s, with error", file, err)
 			continue
 		}
-		err = yaml.Unmarshal(bts, &m)
-		if err != nil {
-			common.ERROR("Failed to unmarshal configuration file: %s, with error", file, err)
-			continue
-		}
-		names = append(names, file)
-		maps = append(maps, m)
+		env.addSource(source, FileSourceOrder)
 		common.INFO("Load configuration file: %s success", file)
 	}
-	env.files = append(env.files, names...)
-	env.configs = append(env.configs, maps...)
 }
 
-func (p *environment) tryLoadOSEnv(key string) (interface{}, bool) {
+// envKey returns the environment variable name of the key, a.b-c -> STELLA_A_B_C
+func envKey(key string) string {
 	key = strings.ReplaceAll(key, ".", "_")
 	key = strings.ReplaceAll(key, "-", "_")
-	key = "STELLA_" + strings.ToUpper(key)
-	value := os.Getenv(key)
-	if value == "" {
-		return nil, false
-	}
-	return value, true
+	return "STELLA_" + strings.ToUpper(key)
 }
 
+// Get returns the value of the key with the placeholders expanded, a value that can not be expanded is logged and
+// returned as is. The ENC(...) values are decrypted by DecryptEnvironment. The source of the value is recorded, see
+// Origins.
 func (p *environment) Get(key string) (interface{}, bool) {
 	once.Do(func() {
 		tryLoadConfig(defaultFiles...)
 	})
 	rwLock.RLock()
 	defer rwLock.RUnlock()
-	value, ok := p.tryLoadOSEnv(key)
-	if ok {
-		return value, ok
+	value, source, ok := p.lookup(key)
+	if !ok {
+		return nil, false
+	}
+	p.origins.Store(key, source)
+	expanded, err := p.expandValue(value, []string{key})
+	if err != nil {
+		common.ERROR("Failed to expand configuration: %s=%v, with error", key, value, err)
+		return value, true
 	}
-	for _, config := range p.configs {
-		value, ok := get(config, key)
-		if ok {
-			return value, true
+	return expanded, true
+}
+
+// lookup returns the raw value of the key and the name of the source, the caller holds the read lock.
+func (p *environment) lookup(key string) (interface{}, string, bool) {
+	for _, e := range p.sources {
+		if value, ok := e.source.Get(key); ok {
+			if _, isEnv := e.source.(*envSource); isEnv {
+				return value, envKey(key), true
+			}
+			return value, e.source.Name(), true
 		}
 	}
-	return nil, false
+	return nil, "", false
+}
+
+// Source returns where the value of the key comes from, the environment variable name or the name of the source.
+func (p *environment) Source(key string) (string, bool) {
+	once.Do(func() {
+		tryLoadConfig(defaultFiles...)
+	})
+	rwLock.RLock()
+	defer rwLock.RUnlock()
+	_, source, ok := p.lookup(key)
+	return source, ok
+}
+
+// Origins returns the source of every key looked up so far.
+func Origins() map[string]string {
+	r := make(map[string]string)
+	env.origins.Range(func(k, v interface{}) bool {
+		r[k.(string)] = v.(string)
+		return true
+	})
+	return r
 }
 
-func (p *environment) GetInt(key string) (int, bool) {
+func (p typedConfig) GetInt(key string) (int, bool) {
 	value, ok := p.Get(key)
 	if !ok {
 		return 0, false
@@ -149,7 +290,7 @@ func (p *environment) GetInt(key string) (int, bool) {
 	}
 }
 
-func (p *environment) GetString(key string) (string, bool) {
+func (p typedConfig) GetString(key string) (string, bool) {
 	value, ok := p.Get(key)
 	if !ok {
 		return "", false
@@ -168,7 +309,7 @@ func (p *environment) GetString(key string) (string, bool) {
 	}
 }
 
-func (p *environment) GetBool(key string) (bool, bool) {
+func (p typedConfig) GetBool(key string) (bool, bool) {
 	value, ok := p.Get(key)
 	if !ok {
 		return false, false
@@ -198,7 +339,7 @@ func (p *environment) GetBool(key string) (bool, bool) {
 	}
 }
 
-func (p *environment) GetOr(key string, defaultValue interface{}) interface{} {
+func (p typedConfig) GetOr(key string, defaultValue interface{}) interface{} {
 	value, ok := p.Get(key)
 	if !ok {
 		return defaultValue
@@ -207,7 +348,7 @@ func (p *environment) GetOr(key string, defaultValue interface{}) interface{} {
 	}
 }
 
-func (p *environment) GetIntOr(key string, defaultValue int) int {
+func (p typedConfig) GetIntOr(key string, defaultValue int) int {
 	value, ok := p.GetInt(key)
 	if !ok {
 		return defaultValue
@@ -216,7 +357,7 @@ func (p *environment) GetIntOr(key string, defaultValue int) int {
 	}
 }
 
-func (p *environment) GetBoolOr(key string, defaultValue bool) bool {
+func (p typedConfig) GetBoolOr(key string, defaultValue bool) bool {
 	value, ok := p.GetBool(key)
 	if !ok {
 		return defaultValue
@@ -225,7 +366,7 @@ func (p *environment) GetBoolOr(key string, defaultValue bool) bool {
 	}
 }
 
-func (p *environment) GetStringOr(key string, defaultValue string) string {
+func (p typedConfig) GetStringOr(key string, defaultValue string) string {
 	value, ok := p.GetString(key)
 	if !ok {
 		return defaultValue
@@ -234,6 +375,118 @@ func (p *environment) GetStringOr(key string, defaultValue string) string {
 	}
 }
 
+// convert converts the value of the key to the type, a value that can not be converted is logged and treated as absent.
+func (p typedConfig) convert(key string, typ reflect.Type) (reflect.Value, bool) {
+	value, ok := p.Get(key)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	convertValue, err := Convert(value, typ)
+	if err != nil {
+		common.ERROR("Failed to get configuration: %s=%v, with error", key, value, err)
+		return reflect.Value{}, false
+	}
+	return convertValue, true
+}
+
+func (p typedConfig) GetInt64(key string) (int64, bool) {
+	value, ok := p.convert(key, reflect.TypeOf(int64(0)))
+	if !ok {
+		return 0, false
+	}
+	return value.Int(), true
+}
+
+func (p typedConfig) GetFloat64(key string) (float64, bool) {
+	value, ok := p.convert(key, reflect.TypeOf(float64(0)))
+	if !ok {
+		return 0, false
+	}
+	return value.Float(), true
+}
+
+// GetDuration accepts 5s, 1m30s or a bare number of milliseconds.
+func (p typedConfig) GetDuration(key string) (time.Duration, bool) {
+	value, ok := p.convert(key, durationType)
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(value.Int()), true
+}
+
+// GetByteSize accepts 200MB, 1.5GiB, 512K or a bare number of bytes, the units are multiples of 1024.
+func (p typedConfig) GetByteSize(key string) (int64, bool) {
+	value, ok := p.Get(key)
+	if !ok {
+		return 0, false
+	}
+	size, err := toByteSize(value)
+	if err != nil {
+		common.ERROR("Failed to get configuration: %s=%v, with error", key, value, err)
+		return 0, false
+	}
+	return size, true
+}
+
+// GetStringSlice accepts a YAML list or a comma separated string.
+func (p typedConfig) GetStringSlice(key string) ([]string, bool) {
+	value, ok := p.convert(key, reflect.TypeOf([]string{}))
+	if !ok {
+		return nil, false
+	}
+	return value.Interface().([]string), true
+}
+
+func (p typedConfig) GetStringMap(key string) (map[string]interface{}, bool) {
+	value, ok := p.convert(key, reflect.TypeOf(map[string]interface{}{}))
+	if !ok {
+		return nil, false
+	}
+	return value.Interface().(map[string]interface{}), true
+}
+
+func (p typedConfig) GetInt64Or(key string, defaultValue int64) int64 {
+	if value, ok := p.GetInt64(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (p typedConfig) GetFloat64Or(key string, defaultValue float64) float64 {
+	if value, ok := p.GetFloat64(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (p typedConfig) GetDurationOr(key string, defaultValue time.Duration) time.Duration {
+	if value, ok := p.GetDuration(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (p typedConfig) GetByteSizeOr(key string, defaultValue int64) int64 {
+	if value, ok := p.GetByteSize(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (p typedConfig) GetStringSliceOr(key string, defaultValue []string) []string {
+	if value, ok := p.GetStringSlice(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (p typedConfig) GetStringMapOr(key string, defaultValue map[string]interface{}) map[string]interface{} {
+	if value, ok := p.GetStringMap(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
 func get(config interface{}, key string) (interface{}, bool) {
 	switch config.(type) {
 	case map[interface{}]interface{}:
@@ -277,6 +530,19 @@ type TypedConfig interface {
 	GetIntOr(key string, defaultValue int) int
 	GetBoolOr(key string, defaultValue bool) bool
 	GetStringOr(key string, defaultValue string) string
+
+	GetInt64(key string) (int64, bool)
+	GetFloat64(key string) (float64, bool)
+	GetDuration(key string) (time.Duration, bool)
+	GetByteSize(key string) (int64, bool)
+	GetStringSlice(key string) ([]string, bool)
+	GetStringMap(key string) (map[string]interface{}, bool)
+	GetInt64Or(key string, defaultValue int64) int64
+	GetFloat64Or(key string, defaultValue float64) float64
+	GetDurationOr(key string, defaultValue time.Duration) time.Duration
+	GetByteSizeOr(key string, defaultValue int64) int64
+	GetStringSliceOr(key string, defaultValue []string) []string
+	GetStringMapOr(key string, defaultValue map[string]interface{}) map[string]interface{}
 }
 
 type ConfigurationEnvironment struct{}
@@ -285,6 +551,14 @@ func (p *ConfigurationEnvironment) Get(key string) (interface{}, bool) {
 	return env.Get(key)
 }
 
+func (p *ConfigurationEnvironment) Source(key string) (string, bool) {
+	return env.Source(key)
+}
+
+func (p *ConfigurationEnvironment) Dump() []*Property {
+	return Dump()
+}
+
 func (p *ConfigurationEnvironment) GetOr(key string, defaultValue interface{}) interface{} {
 	return env.GetOr(key, defaultValue)
 }
@@ -313,63 +587,174 @@ func (p *ConfigurationEnvironment) GetStringOr(key string, defaultValue string)
 	return env.GetStringOr(key, defaultValue)
 }
 
+func (p *ConfigurationEnvironment) GetInt64(key string) (int64, bool) {
+	return env.GetInt64(key)
+}
+
+func (p *ConfigurationEnvironment) GetFloat64(key string) (float64, bool) {
+	return env.GetFloat64(key)
+}
+
+func (p *ConfigurationEnvironment) GetDuration(key string) (time.Duration, bool) {
+	return env.GetDuration(key)
+}
+
+func (p *ConfigurationEnvironment) GetByteSize(key string) (int64, bool) {
+	return env.GetByteSize(key)
+}
+
+func (p *ConfigurationEnvironment) GetStringSlice(key string) ([]string, bool) {
+	return env.GetStringSlice(key)
+}
+
+func (p *ConfigurationEnvironment) GetStringMap(key string) (map[string]interface{}, bool) {
+	return env.GetStringMap(key)
+}
+
+func (p *ConfigurationEnvironment) GetInt64Or(key string, defaultValue int64) int64 {
+	return env.GetInt64Or(key, defaultValue)
+}
+
+func (p *ConfigurationEnvironment) GetFloat64Or(key string, defaultValue float64) float64 {
+	return env.GetFloat64Or(key, defaultValue)
+}
+
+func (p *ConfigurationEnvironment) GetDurationOr(key string, defaultValue time.Duration) time.Duration {
+	return env.GetDurationOr(key, defaultValue)
+}
+
+func (p *ConfigurationEnvironment) GetByteSizeOr(key string, defaultValue int64) int64 {
+	return env.GetByteSizeOr(key, defaultValue)
+}
+
+func (p *ConfigurationEnvironment) GetStringSliceOr(key string, defaultValue []string) []string {
+	return env.GetStringSliceOr(key, defaultValue)
+}
+
+func (p *ConfigurationEnvironment) GetStringMapOr(key string, defaultValue map[string]interface{}) map[string]interface{} {
+	return env.GetStringMapOr(key, defaultValue)
+}
+
 type Cipher interface {
 	Decrypt(string) (string, error)
 }
 
+// DecryptEnvironment decrypts the ENC(...) values of Config, or of the global configuration when Config is nil,
+// with Cipher. Plaintext values are returned as is, a value that can not be decrypted is logged and returned as is.
 type DecryptEnvironment struct {
 	Cipher Cipher
+	Config Config
 }
 
 func (p *DecryptEnvironment) Get(key string) (interface{}, bool) {
-	return env.Get(key)
+	var value interface{}
+	var ok bool
+	if p.Config == nil {
+		value, ok = env.Get(key)
+	} else {
+		value, ok = p.Config.Get(key)
+	}
+	if !ok {
+		return nil, false
+	}
+	decrypted, ok, err := decryptValue(value, p.Cipher)
+	if err != nil {
+		common.ERROR("Failed to decrypt configuration: %s=%v, with error", key, value, err)
+		return value, true
+	}
+	if ok {
+		markDecrypted(key)
+	}
+	return decrypted, true
+}
+
+func (p *DecryptEnvironment) Source(key string) (string, bool) {
+	if source, ok := p.Config.(SourceConfig); ok {
+		return source.Source(key)
+	}
+	return env.Source(key)
+}
+
+// Dump lists the keys of the wrapped Config, or of the configuration environment when there is none.
+func (p *DecryptEnvironment) Dump() []*Property {
+	if p.Config == nil {
+		return Dump()
+	}
+	return DumpOf(p.Config)
 }
 
 func (p *DecryptEnvironment) GetOr(key string, defaultValue interface{}) interface{} {
-	return env.GetOr(key, defaultValue)
+	return typedConfig{p}.GetOr(key, defaultValue)
 }
 
 func (p *DecryptEnvironment) GetInt(key string) (int, bool) {
-	return env.GetInt(key)
+	return typedConfig{p}.GetInt(key)
 }
 
 func (p *DecryptEnvironment) GetString(key string) (string, bool) {
-	if p.Cipher == nil {
-		return env.GetString(key)
-	}
-	if value, ok := env.GetString(key); ok {
-		if srcVal, err := p.Cipher.Decrypt(value); err != nil {
-			common.ERROR("Failed to decrypt configuration: %s=%s, with error", key, value, err)
-			panic(err)
-		} else {
-			return srcVal, ok
-		}
-	} else {
-		return value, ok
-	}
+	return typedConfig{p}.GetString(key)
 }
 
 func (p *DecryptEnvironment) GetBool(key string) (bool, bool) {
-	return env.GetBool(key)
+	return typedConfig{p}.GetBool(key)
 }
 
 func (p *DecryptEnvironment) GetIntOr(key string, defaultValue int) int {
-	return env.GetIntOr(key, defaultValue)
+	return typedConfig{p}.GetIntOr(key, defaultValue)
 }
 
 func (p *DecryptEnvironment) GetBoolOr(key string, defaultValue bool) bool {
-	return env.GetBoolOr(key, defaultValue)
+	return typedConfig{p}.GetBoolOr(key, defaultValue)
 }
 
 func (p *DecryptEnvironment) GetStringOr(key string, defaultValue string) string {
-	if p.Cipher == nil {
-		return env.GetStringOr(key, defaultValue)
-	}
-	value := env.GetStringOr(key, defaultValue)
-	if srcVal, err := p.Cipher.Decrypt(value); err != nil {
-		common.ERROR("Failed to decrypt configuration: %s=%s, with error", key, value, err)
-		return defaultValue
-	} else {
-		return srcVal
-	}
+	return typedConfig{p}.GetStringOr(key, defaultValue)
+}
+
+func (p *DecryptEnvironment) GetInt64(key string) (int64, bool) {
+	return typedConfig{p}.GetInt64(key)
+}
+
+func (p *DecryptEnvironment) GetFloat64(key string) (float64, bool) {
+	return typedConfig{p}.GetFloat64(key)
+}
+
+func (p *DecryptEnvironment) GetDuration(key string) (time.Duration, bool) {
+	return typedConfig{p}.GetDuration(key)
+}
+
+func (p *DecryptEnvironment) GetByteSize(key string) (int64, bool) {
+	return typedConfig{p}.GetByteSize(key)
+}
+
+func (p *DecryptEnvironment) GetStringSlice(key string) ([]string, bool) {
+	return typedConfig{p}.GetStringSlice(key)
+}
+
+func (p *DecryptEnvironment) GetStringMap(key string) (map[string]interface{}, bool) {
+	return typedConfig{p}.GetStringMap(key)
+}
+
+func (p *DecryptEnvironment) GetInt64Or(key string, defaultValue int64) int64 {
+	return typedConfig{p}.GetInt64Or(key, defaultValue)
+}
+
+func (p *DecryptEnvironment) GetFloat64Or(key string, defaultValue float64) float64 {
+	return typedConfig{p}.GetFloat64Or(key, defaultValue)
+}
+
+func (p *DecryptEnvironment) GetDurationOr(key string, defaultValue time.Duration) time.Duration {
+	return typedConfig{p}.GetDurationOr(key, defaultValue)
+}
+
+func (p *DecryptEnvironment) GetByteSizeOr(key string, defaultValue int64) int64 {
+	return typedConfig{p}.GetByteSizeOr(key, defaultValue)
+}
+
+func (p *DecryptEnvironment) GetStringSliceOr(key string, defaultValue []string) []string {
+	return typedConfig{p}.GetStringSliceOr(key, defaultValue)
+}
+
+func (p *DecryptEnvironment) GetStringMapOr(key string, defaultValue map[string]interface{}) map[string]interface{} {
+	return typedConfig{p}.GetStringMapOr(key, defaultValue)
 }
@@ -16,7 +16,10 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestConfig(t *testing.T) {
@@ -27,3 +30,504 @@ func TestConfig(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "application.yml"), []byte("profiles.active: dev\na: base\nb: base\nc: base\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "application-dev.yml"), []byte("a: dev\nb: dev\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "application-local.yml"), []byte("a: local\n"), 0644)
+
+	env = newEnvironment()
+	os.Setenv(ProfilesEnvKey, "dev,local")
+	defer os.Unsetenv(ProfilesEnvKey)
+	tryLoadConfig(filepath.Join(dir, "application.yml"))
+
+	if profiles := env.profiles; len(profiles) != 2 || profiles[0] != "dev" || profiles[1] != "local" {
+		t.Fatal(profiles)
+	}
+	for key, expected := range map[string]string{"a": "local", "b": "dev", "c": "base", ProfilesActiveKey: "dev,local"} {
+		if v, ok := env.GetString(key); !ok || v != expected {
+			t.Fatal(key, v)
+		}
+	}
+
+	env = newEnvironment()
+	os.Unsetenv(ProfilesEnvKey)
+	tryLoadConfig(filepath.Join(dir, "application.yml"))
+	if v, _ := env.GetString("a"); v != "dev" {
+		t.Fatal(v)
+	}
+}
+
+type listener struct {
+	keys []string
+}
+
+func (l *listener) OnConfigChange(keys []string) {
+	l.keys = keys
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "application.yml")
+	os.WriteFile(file, []byte("a:\n  b: 1\n  c: 2\nd: 3\nf: ${a.c}\n"), 0644)
+
+	env = newEnvironment()
+	tryLoadConfig(file)
+	l := &listener{}
+	AddChangeListener(l)
+	defer RemoveChangeListener(l)
+
+	os.WriteFile(file, []byte("a:\n  b: 1\n  c: 20\ne: 4\nf: ${a.c}\n"), 0644)
+	os.Chtimes(file, time.Now(), time.Now().Add(time.Second))
+	if err := Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(l.keys, ",") != "a.c,d,e,f" {
+		t.Fatal(l.keys)
+	}
+	if v, _ := env.GetInt("a.c"); v != 20 {
+		t.Fatal(v)
+	}
+	if !ContainsKey(l.keys, "a") || ContainsKey(l.keys, "a.b") {
+		t.FailNow()
+	}
+}
+
+type redisSettings struct {
+	Addr        string        `config:"default='127.0.0.1:6379'"`
+	DialTimeout time.Duration `config:"default='5s'"`
+	ReadTimeout time.Duration
+	PoolSize    int
+	Sentinels   []string
+	Labels      map[string]string
+	TLS         *struct {
+		Enable bool
+	}
+	Nodes map[string]struct {
+		Weight int `config:"default='1'"`
+	}
+	Ignored string `config:"-"`
+}
+
+func TestBind(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "application.yml")
+	os.WriteFile(file, []byte(`redis:
+  read-timeout: 200
+  poolSize: 10
+  sentinels:
+    - a:26379
+    - b:26379
+  labels:
+    app: siu
+  tls:
+    enable: true
+  nodes:
+    n1:
+      weight: 3
+    n2: {}
+  ignored: value
+`), 0644)
+
+	env = newEnvironment()
+	tryLoadConfig(file)
+	os.Setenv("STELLA_REDIS_DIAL_TIMEOUT", "1m")
+	defer os.Unsetenv("STELLA_REDIS_DIAL_TIMEOUT")
+
+	s := &redisSettings{}
+	if err := Bind("redis", s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Addr != "127.0.0.1:6379" || s.DialTimeout != time.Minute || s.ReadTimeout != 200*time.Millisecond || s.PoolSize != 10 {
+		t.Fatal(s)
+	}
+	if strings.Join(s.Sentinels, ",") != "a:26379,b:26379" || s.Labels["app"] != "siu" || s.TLS == nil || !s.TLS.Enable {
+		t.Fatal(s)
+	}
+	if len(s.Nodes) != 2 || s.Nodes["n1"].Weight != 3 || s.Nodes["n2"].Weight != 1 || s.Ignored != "" {
+		t.Fatal(s)
+	}
+
+	os.Setenv("STELLA_REDIS_POOLSIZE", "ten")
+	defer os.Unsetenv("STELLA_REDIS_POOLSIZE")
+	if err := Bind("redis", &redisSettings{}); err == nil || !strings.Contains(err.Error(), "redis.poolSize") {
+		t.Fatal(err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "application.yml")
+	os.WriteFile(file, []byte(`server:
+  port: 80
+  mode: prod
+  name: siu
+  timeout: 10ms
+  hosts: [a.com, b]
+  token: ENC(nekot)
+  retries: ENC(3)
+`), 0644)
+
+	env = newEnvironment()
+	tryLoadConfig(file)
+	os.Setenv("STELLA_SERVER_WORKERS", "many")
+	defer os.Unsetenv("STELLA_SERVER_WORKERS")
+
+	type Server struct {
+		Port    int           `config:"min='1024',max='65535'"`
+		Mode    string        `config:"enum='debug,release,test'"`
+		Name    string        `config:"regex='^[a-z]+$',required='true'"`
+		Timeout time.Duration `config:"min='1s'"`
+		Hosts   []string      `config:"regex='\\.com$'"`
+		Workers int
+		Secret  string `config:"required='true'"`
+		Addr    string `config:"one-of='endpoint'"`
+		Socket  string `config:"one-of='endpoint'"`
+		Level   string `config:"default='trace',enum='debug,info'"`
+		Token   string `config:"regex='^[a-z]{5}$'"`
+		Retries int    `config:"min='1'"`
+		Code    string `config:"default='a1',regex='^[a-z]\\d$'"`
+	}
+	server := &Server{}
+	err := Bind("server", server)
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatal(err)
+	}
+	t.Log(ve)
+	expected := []string{"server.addr|server.socket", "server.hosts", "server.level", "server.mode", "server.port", "server.secret", "server.timeout", "server.workers"}
+	keys := make([]string, 0)
+	for _, fe := range ve.Errors {
+		keys = append(keys, fe.Key)
+	}
+	if strings.Join(keys, ",") != strings.Join(expected, ",") {
+		t.Fatal(keys)
+	}
+	if server.Token != "ENC(nekot)" || server.Retries != 0 {
+		t.Fatal("encrypted values are validated once decrypted", server.Token, server.Retries)
+	}
+	BindFrom(&DecryptEnvironment{Cipher: reverseCipher{}}, "server", server)
+	if server.Token != "token" || server.Retries != 3 {
+		t.Fatal(server.Token, server.Retries)
+	}
+	sources := map[string]string{"server.port": file, "server.workers": "STELLA_SERVER_WORKERS", "server.level": "default", "server.secret": ""}
+	for _, fe := range ve.Errors {
+		if source, ok := sources[fe.Key]; ok && fe.Source != source {
+			t.Fatal(fe)
+		}
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag      string
+		key      string
+		expected string
+	}{
+		{`regex='^\d+\.\d+$'`, "regex", `^\d+\.\d+$`},
+		{`default='it\'s'`, "default", "it's"},
+		{`enum='a\,b,c',name='x'`, "enum", "a,b,c"},
+		{`default='a\=b'`, "default", "a=b"},
+		{`regex='\w\'\s'`, "regex", `\w'\s`},
+	}
+	for _, tt := range tests {
+		tagMap, err := ParseTag(tt.tag)
+		if err != nil || tagMap[tt.key] != tt.expected {
+			t.Fatal(tt.tag, tagMap, err)
+		}
+	}
+}
+
+func TestTypedGetters(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "application.yml")
+	os.WriteFile(file, []byte(`a:
+  int64: 9007199254740993
+  float: 1.5
+  duration: 1m30s
+  millis: 200
+  size: 1.5GB
+  bytes: 1024
+  list: [a, b]
+  csv: "x, y,z"
+  map:
+    k1: v1
+    k2: 2
+  bad: abc
+`), 0644)
+
+	env = newEnvironment()
+	tryLoadConfig(file)
+	os.Setenv("STELLA_A_ENV_DURATION", "250")
+	defer os.Unsetenv("STELLA_A_ENV_DURATION")
+
+	if v, ok := env.GetInt64("a.int64"); !ok || v != 9007199254740993 {
+		t.Fatal(v)
+	}
+	if v, ok := env.GetFloat64("a.float"); !ok || v != 1.5 {
+		t.Fatal(v)
+	}
+	for key, expected := range map[string]time.Duration{"a.duration": 90 * time.Second, "a.millis": 200 * time.Millisecond, "a.env.duration": 250 * time.Millisecond} {
+		if v, ok := env.GetDuration(key); !ok || v != expected {
+			t.Fatal(key, v)
+		}
+	}
+	if v, ok := env.GetByteSize("a.size"); !ok || v != 3<<29 {
+		t.Fatal(v)
+	}
+	if v, ok := env.GetByteSize("a.bytes"); !ok || v != 1024 {
+		t.Fatal(v)
+	}
+	if v, ok := env.GetStringSlice("a.list"); !ok || strings.Join(v, ",") != "a,b" {
+		t.Fatal(v)
+	}
+	if v, ok := env.GetStringSlice("a.csv"); !ok || strings.Join(v, ",") != "x,y,z" {
+		t.Fatal(v)
+	}
+	if v, ok := env.GetStringMap("a.map"); !ok || len(v) != 2 || v["k1"] != "v1" || v["k2"] != 2 {
+		t.Fatal(v)
+	}
+	if v := env.GetDurationOr("a.bad", time.Second); v != time.Second {
+		t.Fatal(v)
+	}
+	if v := env.GetByteSizeOr("a.bad", 1); v != 1 {
+		t.Fatal(v)
+	}
+	if v := env.GetStringSliceOr("a.none", []string{"d"}); len(v) != 1 || v[0] != "d" {
+		t.Fatal(v)
+	}
+}
+
+func TestPlaceholder(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "application.yml")
+	os.WriteFile(file, []byte(`db:
+  host: 127.0.0.1
+  port: 3306
+  timeout: ${db.port}
+gorm:
+  addr: ${db.host}:${db.port}
+  dsn: ${gorm.addr}/${db.name:siu}?${SIU_TEST_PARAMS}
+  nested: ${db.missing:${db.host}}
+  literal: $${db.host}
+  list: ["${db.host}", b]
+cycle:
+  a: ${cycle.b}
+  b: x-${cycle.a}
+missing: ${db.missing}
+`), 0644)
+
+	env = newEnvironment()
+	tryLoadConfig(file)
+	os.Setenv("SIU_TEST_PARAMS", "charset=utf8mb4")
+	defer os.Unsetenv("SIU_TEST_PARAMS")
+
+	for key, expected := range map[string]string{"gorm.addr": "127.0.0.1:3306", "gorm.dsn": "127.0.0.1:3306/siu?charset=utf8mb4", "gorm.nested": "127.0.0.1", "gorm.literal": "${db.host}"} {
+		if v, ok := env.GetString(key); !ok || v != expected {
+			t.Fatal(key, v)
+		}
+	}
+	if v, ok := env.GetInt("db.timeout"); !ok || v != 3306 {
+		t.Fatal(v)
+	}
+	if v, ok := env.GetStringSlice("gorm.list"); !ok || strings.Join(v, ",") != "127.0.0.1,b" {
+		t.Fatal(v)
+	}
+	if _, err := Expand("${cycle.a}"); err == nil || !strings.Contains(err.Error(), "cycle.a -> cycle.b -> cycle.a") {
+		t.Fatal(err)
+	}
+	if _, err := Expand("${missing}"); err == nil || !strings.Contains(err.Error(), "${db.missing}") {
+		t.Fatal(err)
+	}
+	if v, _ := env.GetString("missing"); v != "${db.missing}" {
+		t.Fatal(v)
+	}
+}
+
+func TestSources(t *testing.T) {
+	dir := t.TempDir()
+	yml := filepath.Join(dir, "application.yml")
+	js := filepath.Join(dir, "application.json")
+	tml := filepath.Join(dir, "application.toml")
+	dotenv := filepath.Join(dir, ".env")
+	os.WriteFile(yml, []byte("a: yml\nb: yml\nc: yml\nd: yml\ne: yml\n"), 0644)
+	os.WriteFile(js, []byte(`{"b": "json", "n": {"int": 3, "float": 1.5}}`), 0644)
+	os.WriteFile(tml, []byte("c = \"toml\"\n[t]\nint = 4\nlist = [\"x\", \"y\"]\n"), 0644)
+	os.WriteFile(dotenv, []byte("# comment\nSTELLA_D=dotenv\nexport DB_HOST='127.0.0.1'\nSTELLA_F=value # comment\n"), 0644)
+
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{"app", "--e=flag", "--g.h=flag", "--i", "-j=single", "--", "--k=ignored"}
+	os.Setenv("STELLA_CONFIG_FILES", dotenv+","+js+","+tml)
+	defer os.Unsetenv("STELLA_CONFIG_FILES")
+	os.Setenv("STELLA_E", "env")
+	defer os.Unsetenv("STELLA_E")
+
+	env = newEnvironment()
+	tryLoadConfig(yml)
+	AddSource(NewMapSource("remote", map[interface{}]interface{}{"a": "remote", "b": "remote"}), RemoteSourceOrder)
+
+	for key, expected := range map[string]string{"a": "remote", "b": "remote", "c": "toml", "d": "dotenv", "e": "flag", "f": "value", "g.h": "flag", "i": "true"} {
+		if v, ok := env.GetString(key); !ok || v != expected {
+			t.Fatal(key, v)
+		}
+	}
+	if v, _ := env.GetStringSlice("t.list"); strings.Join(v, ",") != "x,y" {
+		t.Fatal(v)
+	}
+	if v, err := Expand("${DB_HOST}"); err != nil || v != "127.0.0.1" {
+		t.Fatal(v, err)
+	}
+	for _, key := range []string{"j", "k"} {
+		if _, ok := env.Get(key); ok {
+			t.Fatal(key)
+		}
+	}
+	if v, ok := env.GetInt("n.int"); !ok || v != 3 {
+		t.Fatal(v)
+	}
+	if v, ok := env.GetFloat64("n.float"); !ok || v != 1.5 {
+		t.Fatal(v)
+	}
+	if v, ok := env.GetInt("t.int"); !ok || v != 4 {
+		t.Fatal(v)
+	}
+	for key, expected := range map[string]string{"a": "remote", "c": tml, "d": dotenv, "e": "command line"} {
+		if v, _ := env.Source(key); v != expected {
+			t.Fatal(key, v)
+		}
+	}
+	if origins := Origins(); origins["b"] != "remote" || origins["n.int"] != js {
+		t.Fatal(origins)
+	}
+	os.Unsetenv("STELLA_E")
+	os.Setenv("STELLA_L", "env")
+	defer os.Unsetenv("STELLA_L")
+	if v, _ := env.Source("l"); v != "STELLA_L" {
+		t.Fatal(v)
+	}
+}
+
+type reverseCipher struct{}
+
+func (reverseCipher) Decrypt(s string) (string, error) {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r), nil
+}
+
+// sliceCipher panics on a short value like a careless cipher would.
+type sliceCipher struct{}
+
+func (sliceCipher) Decrypt(s string) (string, error) {
+	return s[:16], nil
+}
+
+func TestDump(t *testing.T) {
+	dir := t.TempDir()
+	yml := filepath.Join(dir, "application.yml")
+	os.WriteFile(yml, []byte("mysql:\n  host: db\n  passwd: root\n  url: ${mysql.host}:3306\n  dsn: root:${mysql.passwd}@${mysql.host}\n  link: ${mysql.dsn}\n  token: x${token}\noss:\n  sk: key\n  disk: ssd\ntoken: ENC(nekot)\njwt:\n  secret: s\ncipher:\n  key: 0011\n  hmac-key: 2233\n  mode: cbc\n"), 0644)
+	os.Setenv("STELLA_MYSQL_HOST", "env")
+	defer os.Unsetenv("STELLA_MYSQL_HOST")
+	os.Setenv("STELLA_EXTRA_PASSWORD", "p")
+	defer os.Unsetenv("STELLA_EXTRA_PASSWORD")
+	os.Setenv("STELLA_CIPHER_KEY", "4455")
+	defer os.Unsetenv("STELLA_CIPHER_KEY")
+
+	env = newEnvironment()
+	tryLoadConfig(yml)
+	if v, _ := (&DecryptEnvironment{Cipher: reverseCipher{}}).GetString("token"); v != "token" {
+		t.Fatal(v)
+	}
+	properties := make(map[string]*Property)
+	for _, p := range Dump() {
+		properties[p.Key] = p
+	}
+	expected := map[string][2]string{
+		"mysql.host":            {"env", "STELLA_MYSQL_HOST"},
+		"mysql.passwd":          {MaskedValue, yml},
+		"mysql.url":             {"env:3306", yml},
+		"mysql.dsn":             {MaskedValue, yml},
+		"mysql.link":            {MaskedValue, yml},
+		"mysql.token":           {MaskedValue, yml},
+		"oss.sk":                {MaskedValue, yml},
+		"oss.disk":              {"ssd", yml},
+		"token":                 {MaskedValue, yml},
+		"jwt.secret":            {MaskedValue, yml},
+		"STELLA_EXTRA_PASSWORD": {MaskedValue, "STELLA_EXTRA_PASSWORD"},
+		"cipher.key":            {MaskedValue, "STELLA_CIPHER_KEY"},
+		"cipher.hmac-key":       {MaskedValue, yml},
+		"cipher.mode":           {"cbc", yml},
+	}
+	for key, e := range expected {
+		p, ok := properties[key]
+		if !ok || p.Value != e[0] || p.Source != e[1] {
+			t.Fatal(key, p)
+		}
+	}
+	if _, ok := properties["STELLA_MYSQL_HOST"]; ok {
+		t.Fatal("STELLA_MYSQL_HOST")
+	}
+	if table := FormatDump(Dump()); !strings.Contains(table, "oss.disk") || strings.Contains(table, "root") {
+		t.Fatal(table)
+	}
+	if len(DumpOf(&DecryptEnvironment{Config: &ConfigurationEnvironment{}})) != len(Dump()) {
+		t.Fatal("dump of the configuration environment")
+	}
+	for key, expected := range map[string]bool{"cipher.key": true, "cipher.hmac-key": true, "app.cipher.private-key": true, "STELLA_CIPHER_KEY": true, "STELLA_CIPHER_HMAC_KEY": true, "cipher.mode": false, "keyring.host": false, "STELLA_SERVER_PORT": false} {
+		if IsSensitive(key) != expected {
+			t.Fatal(key)
+		}
+	}
+}
+
+func TestDecrypt(t *testing.T) {
+	dir := t.TempDir()
+	yml := filepath.Join(dir, "application.yml")
+	os.WriteFile(yml, []byte("db:\n  host: db\n  password: ENC(toor)\n  url: root:${db.password}@${db.host}\n  port: ENC(6033)\n  hosts: [ENC(a), b]\nplain: ENC\n"), 0644)
+
+	env = newEnvironment()
+	tryLoadConfig(yml)
+	if v, _ := env.GetString("db.password"); v != "ENC(toor)" {
+		t.Fatal(v)
+	}
+	e := &DecryptEnvironment{Cipher: reverseCipher{}}
+	for key, expected := range map[string]string{"db.password": "root", "db.url": "root:root@db", "db.host": "db", "plain": "ENC"} {
+		if v, _ := e.GetString(key); v != expected {
+			t.Fatal(key, v)
+		}
+	}
+	if v := e.GetIntOr("db.port", 0); v != 3306 {
+		t.Fatal(v)
+	}
+	if v, _ := e.GetStringSlice("db.hosts"); strings.Join(v, ",") != "a,b" {
+		t.Fatal(v)
+	}
+	if v := e.GetOr("db.password", ""); v != "root" {
+		t.Fatal(v)
+	}
+	if v, _ := e.GetInt64("db.port"); v != 3306 {
+		t.Fatal(v)
+	}
+	if v, _ := (&DecryptEnvironment{}).GetString("db.password"); v != "ENC(toor)" {
+		t.Fatal(v)
+	}
+	if v, _ := (&DecryptEnvironment{Cipher: sliceCipher{}}).GetString("db.password"); v != "ENC(toor)" {
+		t.Fatal(v)
+	}
+	wrapped := &DecryptEnvironment{Cipher: reverseCipher{}, Config: &DecryptEnvironment{}}
+	if v := wrapped.GetStringOr("db.url", ""); v != "root:root@db" {
+		t.Fatal(v)
+	}
+	if v, _ := wrapped.Source("db.host"); v != yml {
+		t.Fatal(v)
+	}
+	for _, p := range Dump() {
+		if p.Key == "db.password" && p.Value != MaskedValue {
+			t.Fatal(p)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Convert converts a configuration value, as it is unmarshaled from YAML or read from an environment
+// variable, to the type. Durations are parsed by time.ParseDuration, bare numbers are milliseconds.
+func Convert(value interface{}, typ reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Zero(typ), nil
+	}
+	if typ == durationType {
+		d, err := toDuration(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(d), nil
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		b, err := toBool(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b).Convert(typ), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := toInt64(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(typ).Elem()
+		if v.OverflowInt(i) {
+			return reflect.Value{}, fmt.Errorf("value %v overflows %s", value, typ)
+		}
+		v.SetInt(i)
+		return v, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, err := toInt64(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(typ).Elem()
+		if i < 0 || v.OverflowUint(uint64(i)) {
+			return reflect.Value{}, fmt.Errorf("value %v overflows %s", value, typ)
+		}
+		v.SetUint(uint64(i))
+		return v, nil
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat64(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(typ).Elem()
+		if v.OverflowFloat(f) {
+			return reflect.Value{}, fmt.Errorf("value %v overflows %s", value, typ)
+		}
+		v.SetFloat(f)
+		return v, nil
+	case reflect.Complex64, reflect.Complex128:
+		s, ok := value.(string)
+		if !ok {
+			s = fmt.Sprintf("%v", value)
+		}
+		c, err := strconv.ParseComplex(s, 128)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(c).Convert(typ), nil
+	case reflect.String:
+		switch value := value.(type) {
+		case string:
+			return reflect.ValueOf(value).Convert(typ), nil
+		case int, int64, float64, bool:
+			return reflect.ValueOf(fmt.Sprintf("%v", value)).Convert(typ), nil
+		}
+	case reflect.Slice:
+		items := toSlice(value)
+		v := reflect.MakeSlice(typ, 0, len(items))
+		for i, item := range items {
+			e, err := Convert(item, typ.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("[%d]: %v", i, err)
+			}
+			v = reflect.Append(v, e)
+		}
+		return v, nil
+	case reflect.Map:
+		m, ok := value.(map[interface{}]interface{})
+		if !ok {
+			break
+		}
+		v := reflect.MakeMapWithSize(typ, len(m))
+		for k, item := range m {
+			key, err := Convert(k, typ.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			e, err := Convert(item, typ.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%v: %v", k, err)
+			}
+			v.SetMapIndex(key, e)
+		}
+		return v, nil
+	case reflect.Struct:
+		m, ok := value.(map[interface{}]interface{})
+		if !ok {
+			break
+		}
+		v := reflect.New(typ).Elem()
+		b := &binder{c: &mapConfig{m}, errs: &ValidationError{}}
+		b.bindStruct("", v)
+		if err := b.errs.Err(); err != nil {
+			return reflect.Value{}, err
+		}
+		return v, nil
+	case reflect.Ptr:
+		e, err := Convert(value, typ.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(typ.Elem())
+		v.Elem().Set(e)
+		return v, nil
+	case reflect.Interface:
+		v := reflect.ValueOf(value)
+		if v.Type().AssignableTo(typ) {
+			return v, nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("can not convert %v to %s", value, typ)
+}
+
+func toBool(value interface{}) (bool, error) {
+	switch value := value.(type) {
+	case bool:
+		return value, nil
+	case int:
+		if value == 0 || value == 1 {
+			return value == 1, nil
+		}
+	case string:
+		return strconv.ParseBool(strings.TrimSpace(value))
+	}
+	return false, fmt.Errorf("can not convert %v to bool", value)
+}
+
+func toInt64(value interface{}) (int64, error) {
+	switch value := value.(type) {
+	case int:
+		return int64(value), nil
+	case int64:
+		return value, nil
+	case uint64:
+		if value > math.MaxInt64 {
+			return 0, fmt.Errorf("value %v overflows int64", value)
+		}
+		return int64(value), nil
+	case float64:
+		if value != math.Trunc(value) {
+			return 0, fmt.Errorf("can not convert %v to integer", value)
+		}
+		return int64(value), nil
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(value), 0, 64)
+	}
+	return 0, fmt.Errorf("can not convert %v to integer", value)
+}
+
+func toFloat64(value interface{}) (float64, error) {
+	switch value := value.(type) {
+	case int:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case uint64:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(value), 64)
+	}
+	return 0, fmt.Errorf("can not convert %v to float", value)
+}
+
+func toDuration(value interface{}) (time.Duration, error) {
+	switch value := value.(type) {
+	case int, int64, uint64, float64:
+		ms, err := toFloat64(value)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(ms * float64(time.Millisecond)), nil
+	case string:
+		value = strings.TrimSpace(value)
+		if ms, err := strconv.ParseFloat(value, 64); err == nil {
+			return time.Duration(ms * float64(time.Millisecond)), nil
+		}
+		return time.ParseDuration(value)
+	}
+	return 0, fmt.Errorf("can not convert %v to duration", value)
+}
+
+var byteSizeUnits = map[string]float64{
+	"":  1,
+	"B": 1,
+	"K": 1 << 10, "KB": 1 << 10, "KIB": 1 << 10,
+	"M": 1 << 20, "MB": 1 << 20, "MIB": 1 << 20,
+	"G": 1 << 30, "GB": 1 << 30, "GIB": 1 << 30,
+	"T": 1 << 40, "TB": 1 << 40, "TIB": 1 << 40,
+}
+
+// toByteSize parses 200MB, 1.5GiB, 512K or a bare number of bytes.
+func toByteSize(value interface{}) (int64, error) {
+	s, ok := value.(string)
+	if !ok {
+		return toInt64(value)
+	}
+	s = strings.TrimSpace(s)
+	i := len(s)
+	for i > 0 && unicode.IsLetter(rune(s[i-1])) {
+		i--
+	}
+	unit, ok := byteSizeUnits[strings.ToUpper(s[i:])]
+	if !ok {
+		return 0, fmt.Errorf("unknown byte size unit %s", s[i:])
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(s[:i]), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("can not convert %s to byte size", s)
+	}
+	return int64(n * unit), nil
+}
+
+// toSlice accepts YAML lists, comma separated strings and single values.
+func toSlice(value interface{}) []interface{} {
+	switch value := value.(type) {
+	case []interface{}:
+		return value
+	case string:
+		items := make([]interface{}, 0)
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				items = append(items, s)
+			}
+		}
+		return items
+	default:
+		return []interface{}{value}
+	}
+}
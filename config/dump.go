@@ -0,0 +1,224 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const MaskedValue = "******"
+
+// SensitiveKeys are the key segments whose values are masked by Dump, sk must match a whole segment.
+var SensitiveKeys = []string{"passwd", "password", "secret", "sk", "private-key"}
+
+var decrypted = &sync.Map{}
+
+// markDecrypted records a key whose ENC(...) value has been decrypted, its value is masked by Dump.
+func markDecrypted(key string) {
+	decrypted.Store(key, struct{}{})
+}
+
+// Property is an effective configuration key.
+type Property struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+	Masked bool        `json:"masked,omitempty"`
+}
+
+// IsSensitive reports whether the value of the key is masked by Dump, the keys under cipher, e.g. cipher.key and
+// cipher.hmac-key, are sensitive as well.
+func IsSensitive(key string) bool {
+	if _, ok := decrypted.Load(key); ok {
+		return true
+	}
+	key = strings.ToLower(key)
+	if strings.HasPrefix(key, "stella_") {
+		// an environment variable, STELLA_CIPHER_KEY -> stella.cipher.key
+		key = strings.ReplaceAll(key, "_", ".")
+	}
+	cipher := false
+	for _, segment := range strings.Split(key, ".") {
+		// the keys of the cipher decrypt every ENC(...) value
+		if cipher && strings.Contains(segment, "key") {
+			return true
+		}
+		cipher = cipher || segment == "cipher"
+		for _, s := range SensitiveKeys {
+			if segment == s || (len(s) > 2 && strings.Contains(segment, s)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Dump returns every effective configuration key sorted by key, with its value and the source it comes from.
+// The sensitive and ENC(...) values are masked. Environment variables and .env entries which do not override a key
+// of the configuration trees are listed by their names.
+func Dump() []*Property {
+	once.Do(func() {
+		tryLoadConfig(defaultFiles...)
+	})
+	rwLock.RLock()
+	defer rwLock.RUnlock()
+
+	keys := make(map[string]struct{})
+	for _, e := range env.sources {
+		if tree, ok := e.source.(TreeSource); ok {
+			flat := make(map[string]interface{})
+			flattenInto(flat, "", tree.Tree())
+			for k := range flat {
+				keys[k] = struct{}{}
+			}
+		}
+	}
+	overridden := make(map[string]struct{})
+	properties := make([]*Property, 0, len(keys))
+	for key := range keys {
+		value, source, ok := env.lookup(key)
+		if !ok {
+			continue
+		}
+		secret := env.referencesSecret(value, []string{key})
+		if expanded, err := env.expandValue(value, []string{key}); err == nil {
+			value = expanded
+		}
+		overridden[envKey(key)] = struct{}{}
+		properties = append(properties, newProperty(key, value, source, secret))
+	}
+	for _, e := range env.sources {
+		switch source := e.source.(type) {
+		case *envSource:
+			for _, kv := range os.Environ() {
+				name, value, _ := strings.Cut(kv, "=")
+				if _, ok := overridden[name]; ok || !strings.HasPrefix(name, "STELLA_") || value == "" {
+					continue
+				}
+				overridden[name] = struct{}{}
+				properties = append(properties, newProperty(name, value, name, false))
+			}
+		case *dotenvSource:
+			for name, value := range source.values {
+				if _, ok := overridden[name]; ok {
+					continue
+				}
+				overridden[name] = struct{}{}
+				properties = append(properties, newProperty(name, value, source.name, false))
+			}
+		}
+	}
+	sort.Slice(properties, func(i, j int) bool {
+		return properties[i].Key < properties[j].Key
+	})
+	return properties
+}
+
+// DumpConfig is a configuration which lists its effective keys, see Dump.
+type DumpConfig interface {
+	Dump() []*Property
+}
+
+// DumpOf returns the effective configuration keys of c, none when c does not implement DumpConfig.
+func DumpOf(c Config) []*Property {
+	if d, ok := c.(DumpConfig); ok {
+		return d.Dump()
+	}
+	return []*Property{}
+}
+
+func newProperty(key string, value interface{}, source string, secret bool) *Property {
+	if secret || IsSensitive(strings.ReplaceAll(strings.ToLower(key), "_", ".")) || IsSensitive(key) || IsEncrypted(toString(value)) {
+		return &Property{Key: key, Value: MaskedValue, Source: source, Masked: true}
+	}
+	return &Property{Key: key, Value: value, Source: source}
+}
+
+// referencesSecret reports whether the placeholders of the value refer to a sensitive key or to an ENC(...) value,
+// directly or through the placeholders of the values they refer to, the caller holds the read lock.
+func (p *environment) referencesSecret(value interface{}, stack []string) bool {
+	switch value := value.(type) {
+	case string:
+		for i := 0; i < len(value); i++ {
+			if strings.HasPrefix(value[i:], "$${") {
+				i += 2
+				continue
+			}
+			if !strings.HasPrefix(value[i:], "${") {
+				continue
+			}
+			end := closingBrace(value, i+2)
+			if end < 0 {
+				return false
+			}
+			key, defaultValue, _ := strings.Cut(value[i+2:end], ":")
+			key = strings.TrimSpace(key)
+			if IsSensitive(key) {
+				return true
+			}
+			if referenced, _, ok := p.lookup(key); ok {
+				for _, k := range stack {
+					if k == key {
+						return false
+					}
+				}
+				if IsEncrypted(toString(referenced)) || p.referencesSecret(referenced, append(append([]string{}, stack...), key)) {
+					return true
+				}
+			} else if p.referencesSecret(defaultValue, stack) {
+				return true
+			}
+			i = end
+		}
+	case []interface{}:
+		for _, v := range value {
+			if p.referencesSecret(v, stack) {
+				return true
+			}
+		}
+	case map[interface{}]interface{}:
+		for _, v := range value {
+			if p.referencesSecret(v, stack) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FormatDump formats the properties as a table.
+func FormatDump(properties []*Property) string {
+	width := [2]int{len("KEY"), len("VALUE")}
+	values := make([]string, len(properties))
+	for i, p := range properties {
+		values[i] = toString(p.Value)
+		if len(p.Key) > width[0] {
+			width[0] = len(p.Key)
+		}
+		if len(values[i]) > width[1] {
+			width[1] = len(values[i])
+		}
+	}
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%-*s  %-*s  %s\n", width[0], "KEY", width[1], "VALUE", "SOURCE")
+	for i, p := range properties {
+		fmt.Fprintf(b, "%-*s  %-*s  %s\n", width[0], p.Key, width[1], values[i], p.Source)
+	}
+	return b.String()
+}
@@ -0,0 +1,152 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Expand replaces the placeholders of value, `${other.key:default}` is resolved from the configuration,
+// then from the environment variable of the same name, e.g. `${HOME}`. `$${` is kept as a literal `${`.
+func Expand(value string) (string, error) {
+	once.Do(func() {
+		tryLoadConfig(defaultFiles...)
+	})
+	rwLock.RLock()
+	defer rwLock.RUnlock()
+	expanded, err := env.expand(value, nil)
+	if err != nil {
+		return "", err
+	}
+	return toString(expanded), nil
+}
+
+// expandValue expands the strings of value, maps and slices are copied.
+func (p *environment) expandValue(value interface{}, stack []string) (interface{}, error) {
+	switch value := value.(type) {
+	case string:
+		return p.expand(value, stack)
+	case []interface{}:
+		r := make([]interface{}, len(value))
+		for i, v := range value {
+			e, err := p.expandValue(v, stack)
+			if err != nil {
+				return nil, err
+			}
+			r[i] = e
+		}
+		return r, nil
+	case map[interface{}]interface{}:
+		r := make(map[interface{}]interface{}, len(value))
+		for k, v := range value {
+			e, err := p.expandValue(v, stack)
+			if err != nil {
+				return nil, err
+			}
+			r[k] = e
+		}
+		return r, nil
+	}
+	return value, nil
+}
+
+// expand replaces the placeholders of s, stack holds the keys being expanded to detect circular references.
+// A string which is exactly one placeholder keeps the type of the referenced value.
+func (p *environment) expand(s string, stack []string) (interface{}, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	b := &strings.Builder{}
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "$${") {
+			b.WriteString("${")
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "${") {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		end := closingBrace(s, i+2)
+		if end < 0 {
+			return nil, fmt.Errorf("placeholder is not closed: %s", s)
+		}
+		value, err := p.resolvePlaceholder(s[i+2:end], stack)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 && end == len(s)-1 {
+			return value, nil
+		}
+		b.WriteString(toString(value))
+		i = end + 1
+	}
+	return b.String(), nil
+}
+
+func (p *environment) resolvePlaceholder(placeholder string, stack []string) (interface{}, error) {
+	key, defaultValue, hasDefault := placeholder, "", false
+	if index := strings.Index(placeholder, ":"); index != -1 {
+		key, defaultValue, hasDefault = placeholder[:index], placeholder[index+1:], true
+	}
+	key = strings.TrimSpace(key)
+	for _, k := range stack {
+		if k == key {
+			return nil, fmt.Errorf("circular placeholder reference: %s -> %s", strings.Join(stack, " -> "), key)
+		}
+	}
+	next := append(append(make([]string, 0, len(stack)+1), stack...), key)
+	if value, _, ok := p.lookup(key); ok {
+		return p.expandValue(value, next)
+	}
+	if value, ok := os.LookupEnv(key); ok {
+		return p.expand(value, next)
+	}
+	if hasDefault {
+		return p.expand(defaultValue, stack)
+	}
+	return nil, fmt.Errorf("placeholder ${%s} can not be resolved", placeholder)
+}
+
+// closingBrace returns the index of the brace closing the placeholder starting at start, nested placeholders are skipped.
+func closingBrace(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+func toString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,264 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/stella-go/siu/common"
+	"gopkg.in/yaml.v2"
+)
+
+// The precedence of the built-in sources, a source with a lower order wins.
+const (
+	FlagSourceOrder   = 100
+	EnvSourceOrder    = 200
+	RemoteSourceOrder = 300
+	FileSourceOrder   = 400
+)
+
+// ConfigSource provides configuration values, the sources are looked up by order and the first one having the key wins.
+type ConfigSource interface {
+	// Name is reported as the source of the values, e.g. the file name
+	Name() string
+	Get(key string) (interface{}, bool)
+}
+
+// TreeSource is a ConfigSource backed by a configuration tree, the tree is used to detect the changed keys on reload.
+type TreeSource interface {
+	ConfigSource
+	Tree() map[interface{}]interface{}
+}
+
+// ReloadableSource is reloaded by Reload and Watch.
+type ReloadableSource interface {
+	ConfigSource
+	// Reload returns the new source, or nil when nothing changed
+	Reload() (ConfigSource, error)
+}
+
+// AddSource adds the source with the order, among the sources of the same order the source added first wins.
+func AddSource(source ConfigSource, order int) {
+	once.Do(func() {
+		tryLoadConfig(defaultFiles...)
+	})
+	rwLock.Lock()
+	defer rwLock.Unlock()
+	env.addSource(source, order)
+	common.INFO("Add configuration source: %s", source.Name())
+}
+
+type mapSource struct {
+	name string
+	tree map[interface{}]interface{}
+}
+
+// NewMapSource creates a source from a configuration tree, the keys of nested maps are joined by dots.
+func NewMapSource(name string, tree map[interface{}]interface{}) TreeSource {
+	return &mapSource{name: name, tree: tree}
+}
+
+func (p *mapSource) Name() string {
+	return p.name
+}
+
+func (p *mapSource) Get(key string) (interface{}, bool) {
+	return get(p.tree, key)
+}
+
+func (p *mapSource) Tree() map[interface{}]interface{} {
+	return p.tree
+}
+
+// fileSource is a YAML, JSON or TOML file, it is reloaded when the modification time changes.
+type fileSource struct {
+	*mapSource
+	modTime time.Time
+}
+
+// NewFileSource reads the file by its extension: .json, .toml, .env, otherwise YAML.
+func NewFileSource(file string) (ConfigSource, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	bts, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".env":
+		values, err := parseDotenv(bts)
+		if err != nil {
+			return nil, err
+		}
+		return &dotenvSource{name: file, values: values}, nil
+	case ".json":
+		m := make(map[string]interface{})
+		decoder := json.NewDecoder(bytes.NewReader(bts))
+		decoder.UseNumber()
+		if err := decoder.Decode(&m); err != nil {
+			return nil, err
+		}
+		return &fileSource{mapSource: &mapSource{name: file, tree: normalize(m).(map[interface{}]interface{})}, modTime: info.ModTime()}, nil
+	case ".toml":
+		m := make(map[string]interface{})
+		if err := toml.Unmarshal(bts, &m); err != nil {
+			return nil, err
+		}
+		return &fileSource{mapSource: &mapSource{name: file, tree: normalize(m).(map[interface{}]interface{})}, modTime: info.ModTime()}, nil
+	default:
+		m := make(map[interface{}]interface{})
+		if err := yaml.Unmarshal(bts, &m); err != nil {
+			return nil, err
+		}
+		return &fileSource{mapSource: &mapSource{name: file, tree: m}, modTime: info.ModTime()}, nil
+	}
+}
+
+func (p *fileSource) Reload() (ConfigSource, error) {
+	info, err := os.Stat(p.name)
+	if err != nil || info.ModTime().Equal(p.modTime) {
+		return nil, nil
+	}
+	return NewFileSource(p.name)
+}
+
+// normalize converts the JSON and TOML values to the types unmarshaled by YAML.
+func normalize(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		m := make(map[interface{}]interface{}, len(value))
+		for k, v := range value {
+			m[k] = normalize(v)
+		}
+		return m
+	case []interface{}:
+		for i, v := range value {
+			value[i] = normalize(v)
+		}
+		return value
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return normalize(i)
+		}
+		f, _ := value.Float64()
+		return f
+	case int64:
+		if value >= math.MinInt && value <= math.MaxInt {
+			return int(value)
+		}
+		return value
+	}
+	return value
+}
+
+// dotenvSource is a .env file of KEY=VALUE lines, a key is looked up by its environment variable name, e.g.
+// STELLA_SERVER_PORT for server.port, or as written, e.g. DB_HOST for ${DB_HOST}.
+type dotenvSource struct {
+	name   string
+	values map[string]string
+}
+
+func (p *dotenvSource) Name() string {
+	return p.name
+}
+
+func (p *dotenvSource) Get(key string) (interface{}, bool) {
+	if value, ok := p.values[envKey(key)]; ok {
+		return value, true
+	}
+	value, ok := p.values[key]
+	return value, ok
+}
+
+func parseDotenv(bts []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(bts))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		index := strings.Index(line, "=")
+		if index <= 0 {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+		key, value := strings.TrimSpace(line[:index]), strings.TrimSpace(line[index+1:])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		} else if i := strings.Index(value, " #"); i != -1 {
+			value = strings.TrimSpace(value[:i])
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// envSource reads the STELLA_ environment variables, server.port -> STELLA_SERVER_PORT.
+type envSource struct{}
+
+func (*envSource) Name() string {
+	return "environment variables"
+}
+
+func (*envSource) Get(key string) (interface{}, bool) {
+	value := os.Getenv(envKey(key))
+	if value == "" {
+		return nil, false
+	}
+	return value, true
+}
+
+// NewFlagSource reads the command line flags of the form --server.port=9090, a flag without value is true.
+func NewFlagSource(args []string) TreeSource {
+	tree := make(map[interface{}]interface{})
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "--") || len(arg) == 2 {
+			continue
+		}
+		key, value := arg[2:], "true"
+		if index := strings.Index(key, "="); index != -1 {
+			key, value = key[:index], key[index+1:]
+		}
+		setPath(tree, strings.Split(key, "."), value)
+	}
+	return NewMapSource("command line", tree)
+}
+
+func setPath(tree map[interface{}]interface{}, path []string, value interface{}) {
+	for _, p := range path[:len(path)-1] {
+		sub, ok := tree[p].(map[interface{}]interface{})
+		if !ok {
+			sub = make(map[interface{}]interface{})
+			tree[p] = sub
+		}
+		tree = sub
+	}
+	tree[path[len(path)-1]] = value
+}
@@ -0,0 +1,80 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseTag parses the struct tag value of the form `name='abc',value='${a.b.c}',default='type'`,
+// the whole tag is kept with the key "tag". A backslash escaping a quote, a comma or an equals mark is removed, the
+// other backslashes are kept, so the regex ^\d+$ is written `config:"regex='^\\d+$'"`.
+func ParseTag(tag string) (map[string]string, error) {
+	r := make(map[string]string)
+
+	syntaxErr := fmt.Errorf("tag syntax error: %s", tag)
+	r["tag"] = tag
+	for tag != "" {
+		// skip leading space and comma
+		i := 0
+		for i < len(tag) && (tag[i] == ' ' || tag[i] == ',') {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+		// scan to equals mark.
+		// a space or a quote is a syntax error
+		i = 0
+		for i < len(tag) && tag[i] != ' ' && tag[i] != '\'' && tag[i] != '=' {
+			i++
+		}
+		if i+1 >= len(tag) || tag[i] != '=' || tag[i+1] != '\'' {
+			return nil, syntaxErr
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		// scan quoted string to find value
+		i = 1
+		for i < len(tag) && tag[i] != '\'' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return nil, syntaxErr
+		}
+		r[name] = unescape(tag[1:i])
+
+		tag = tag[i+1:]
+	}
+	return r, nil
+}
+
+// unescape removes the backslashes escaping the delimiters of the tag.
+func unescape(value string) string {
+	b := &strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) && strings.IndexByte("',=", value[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
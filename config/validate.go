@@ -0,0 +1,199 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SourceConfig reports where a configuration value comes from.
+type SourceConfig interface {
+	Source(key string) (string, bool)
+}
+
+// FieldError is an invalid configuration key.
+type FieldError struct {
+	Key     string
+	Value   interface{}
+	Source  string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	source := e.Source
+	if source == "" {
+		source = "not set"
+	}
+	if e.Value == nil {
+		return fmt.Sprintf("%s (%s): %s", e.Key, source, e.Message)
+	}
+	return fmt.Sprintf("%s=%v (%s): %s", e.Key, e.Value, source, e.Message)
+}
+
+// ValidationError aggregates every invalid configuration key, it is returned by Bind.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	lines = append(lines, fmt.Sprintf("invalid configuration, %d error(s):", len(e.Errors)))
+	for _, fe := range e.Errors {
+		lines = append(lines, "  - "+fe.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Append adds the errors, a ValidationError is flattened and a duplicated key is reported once.
+func (e *ValidationError) Append(err error) {
+	if err == nil {
+		return
+	}
+	errs := []*FieldError{}
+	var ve *ValidationError
+	var fe *FieldError
+	if errors.As(err, &ve) {
+		errs = ve.Errors
+	} else if errors.As(err, &fe) {
+		errs = []*FieldError{fe}
+	} else {
+		errs = []*FieldError{{Message: err.Error()}}
+	}
+	for _, n := range errs {
+		duplicated := false
+		for _, o := range e.Errors {
+			if o.Error() == n.Error() {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			e.Errors = append(e.Errors, n)
+		}
+	}
+}
+
+// Err returns nil when there is no error.
+func (e *ValidationError) Err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	sort.SliceStable(e.Errors, func(i, j int) bool {
+		return e.Errors[i].Key < e.Errors[j].Key
+	})
+	return e
+}
+
+// validate checks the rules of the config tag: required, min, max, regex and enum.
+// present reports whether the key is configured, v is the bound value.
+func validate(tagMap map[string]string, present bool, v reflect.Value) string {
+	if required, ok := tagMap["required"]; ok && required == "true" && !present {
+		return "is required"
+	}
+	if !present {
+		if _, ok := tagMap["default"]; !ok {
+			return ""
+		}
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if min, ok := tagMap["min"]; ok {
+		if n, limit, err := measure(v, min); err != nil {
+			return err.Error()
+		} else if n < limit {
+			return fmt.Sprintf("must be at least %s", min)
+		}
+	}
+	if max, ok := tagMap["max"]; ok {
+		if n, limit, err := measure(v, max); err != nil {
+			return err.Error()
+		} else if n > limit {
+			return fmt.Sprintf("must be at most %s", max)
+		}
+	}
+	if pattern, ok := tagMap["regex"]; ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Sprintf("invalid regex rule %s", pattern)
+		}
+		for _, s := range stringsOf(v) {
+			if !re.MatchString(s) {
+				return fmt.Sprintf("must match %s", pattern)
+			}
+		}
+	}
+	if enum, ok := tagMap["enum"]; ok {
+		values := make(map[string]struct{})
+		for _, e := range strings.Split(enum, ",") {
+			values[strings.TrimSpace(e)] = struct{}{}
+		}
+		for _, s := range stringsOf(v) {
+			if _, ok := values[s]; !ok {
+				return fmt.Sprintf("must be one of %s", enum)
+			}
+		}
+	}
+	return ""
+}
+
+// measure returns the number compared by min and max, the length for strings, slices and maps.
+func measure(v reflect.Value, limit string) (float64, float64, error) {
+	if v.Type() == durationType {
+		d, err := toDuration(limit)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid limit %s", limit)
+		}
+		return float64(v.Int()), float64(d), nil
+	}
+	l, err := strconv.ParseFloat(limit, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid limit %s", limit)
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), l, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), l, nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), l, nil
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), l, nil
+	}
+	return 0, 0, fmt.Errorf("min/max is not supported by %s", v.Type())
+}
+
+// stringsOf returns the value as strings, every element of a slice is checked by regex and enum.
+func stringsOf(v reflect.Value) []string {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		r := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			r = append(r, fmt.Sprintf("%v", v.Index(i).Interface()))
+		}
+		return r
+	default:
+		return []string{fmt.Sprintf("%v", v.Interface())}
+	}
+}
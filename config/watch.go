@@ -0,0 +1,188 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stella-go/siu/common"
+)
+
+const (
+	WatchKey         = "config.watch"
+	WatchDisableKey  = WatchKey + ".disable"
+	WatchIntervalKey = WatchKey + ".interval"
+)
+
+type ConfigChangeListener interface {
+	// keys are the effective configuration keys whose values have been changed, added or removed
+	OnConfigChange(keys []string)
+}
+
+var (
+	listenerLock = &sync.Mutex{}
+	listeners    = make([]ConfigChangeListener, 0)
+)
+
+func AddChangeListener(l ...ConfigChangeListener) {
+	listenerLock.Lock()
+	defer listenerLock.Unlock()
+	listeners = append(listeners, l...)
+}
+
+func RemoveChangeListener(l ConfigChangeListener) {
+	listenerLock.Lock()
+	defer listenerLock.Unlock()
+	for i, listener := range listeners {
+		if listener == l {
+			listeners = append(listeners[:i], listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// ContainsKey reports whether one of the changed keys is one of the keys or belongs to their subtrees.
+func ContainsKey(changed []string, keys ...string) bool {
+	for _, c := range changed {
+		for _, k := range keys {
+			if c == k || strings.HasPrefix(c, k+".") || strings.HasPrefix(k, c+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Reload reloads the changed sources, e.g. the modified configuration files, swaps them and notifies the listeners.
+func Reload() error {
+	once.Do(func() {
+		tryLoadConfig(defaultFiles...)
+	})
+	rwLock.RLock()
+	entries := append([]*sourceEntry{}, env.sources...)
+	rwLock.RUnlock()
+
+	reloaded := make(map[*sourceEntry]ConfigSource)
+	for _, e := range entries {
+		reloadable, ok := e.source.(ReloadableSource)
+		if !ok {
+			continue
+		}
+		source, err := reloadable.Reload()
+		if err != nil {
+			return fmt.Errorf("failed to reload configuration source: %s, %v", e.source.Name(), err)
+		}
+		if source != nil {
+			reloaded[e] = source
+			common.INFO("Reload configuration source: %s success", e.source.Name())
+		}
+	}
+	if len(reloaded) == 0 {
+		return nil
+	}
+
+	rwLock.Lock()
+	before := env.flattenExpanded()
+	for e, source := range reloaded {
+		e.source = source
+	}
+	after := env.flattenExpanded()
+	rwLock.Unlock()
+	keys := make([]string, 0)
+	for k, v := range after {
+		if old, ok := before[k]; !ok || !reflect.DeepEqual(old, v) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	common.INFO("Configuration changed: %s", strings.Join(keys, ","))
+
+	listenerLock.Lock()
+	ls := append([]ConfigChangeListener{}, listeners...)
+	listenerLock.Unlock()
+	for _, l := range ls {
+		l.OnConfigChange(keys)
+	}
+	return nil
+}
+
+// Watch polls the configuration files every interval and reloads them when they change, call stop to end watching.
+func Watch(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := Reload(); err != nil {
+					common.ERROR("Failed to reload configuration, keep using the previous one, with error", err)
+				}
+			}
+		}
+	}()
+	stopOnce := &sync.Once{}
+	return func() {
+		stopOnce.Do(func() {
+			close(done)
+		})
+	}
+}
+
+// flattenExpanded returns the effective leaf values of the tree sources with the placeholders expanded,
+// so that a key referencing a changed key changes too.
+func (p *environment) flattenExpanded() map[string]interface{} {
+	r := make(map[string]interface{})
+	for i := len(p.sources) - 1; i >= 0; i-- {
+		if tree, ok := p.sources[i].source.(TreeSource); ok {
+			flattenInto(r, "", tree.Tree())
+		}
+	}
+	for k, v := range r {
+		if expanded, err := p.expandValue(v, []string{k}); err == nil {
+			r[k] = expanded
+		}
+	}
+	return r
+}
+
+func flattenInto(r map[string]interface{}, prefix string, m map[interface{}]interface{}) {
+	for k, v := range m {
+		key := fmt.Sprintf("%v", k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if sub, ok := v.(map[interface{}]interface{}); ok {
+			flattenInto(r, key, sub)
+		} else {
+			r[key] = v
+		}
+	}
+}
@@ -15,16 +15,22 @@
 package siu
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"reflect"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stella-go/logger"
@@ -66,19 +72,27 @@ const (
 	BeanRegisterOrder
 )
 
+const (
+	// InjectAllowCircularReferencesKey disables the injection of objects depending on themselves through pointer
+	// fields when it is false.
+	InjectAllowCircularReferencesKey = "inject.allow-circular-references"
+	// InjectDestroyTimeoutKey limits the time each inject.Disposable bean takes to be destroyed at shutdown.
+	InjectDestroyTimeoutKey = "inject.destroy-timeout"
+)
+
 type buildinLogger struct {
 	l        *log.Logger
-	logLevel logger.Level
+	logLevel int64
 	tag      string
 }
 
 func newBuildinLogger(logLevel logger.Level, tag string, writer io.Writer) *buildinLogger {
 	l := log.New(writer, "", log.LstdFlags)
-	return &buildinLogger{l: l, logLevel: logLevel, tag: tag}
+	return &buildinLogger{l: l, logLevel: int64(logLevel), tag: tag}
 }
 
 func (p *buildinLogger) DEBUG(format string, arr ...interface{}) {
-	if p.logLevel <= logger.DebugLevel {
+	if p.Level() <= logger.DebugLevel {
 		if len(arr) > 0 {
 			if _, ok := arr[len(arr)-1].(error); ok {
 				format += " %v"
@@ -90,7 +104,7 @@ func (p *buildinLogger) DEBUG(format string, arr ...interface{}) {
 }
 
 func (p *buildinLogger) INFO(format string, arr ...interface{}) {
-	if p.logLevel <= logger.InfoLevel {
+	if p.Level() <= logger.InfoLevel {
 		if len(arr) > 0 {
 			if _, ok := arr[len(arr)-1].(error); ok {
 				format += " %v"
@@ -102,7 +116,7 @@ func (p *buildinLogger) INFO(format string, arr ...interface{}) {
 }
 
 func (p *buildinLogger) WARN(format string, arr ...interface{}) {
-	if p.logLevel <= logger.WarnLevel {
+	if p.Level() <= logger.WarnLevel {
 		if len(arr) > 0 {
 			if _, ok := arr[len(arr)-1].(error); ok {
 				format += " %v"
@@ -114,7 +128,7 @@ func (p *buildinLogger) WARN(format string, arr ...interface{}) {
 }
 
 func (p *buildinLogger) ERROR(format string, arr ...interface{}) {
-	if p.logLevel <= logger.ErrorLevel {
+	if p.Level() <= logger.ErrorLevel {
 		if len(arr) > 0 {
 			if _, ok := arr[len(arr)-1].(error); ok {
 				format += " %v"
@@ -126,14 +140,21 @@ func (p *buildinLogger) ERROR(format string, arr ...interface{}) {
 }
 
 func (p *buildinLogger) Level() logger.Level {
-	return p.logLevel
+	return logger.Level(atomic.LoadInt64(&p.logLevel))
+}
+
+func (p *buildinLogger) SetLevel(logLevel logger.Level) {
+	atomic.StoreInt64(&p.logLevel, int64(logLevel))
 }
 
 func (p *buildinLogger) Tag() string {
 	return p.tag
 }
 
-type context struct {
+// Application owns the configuration, logger, bean container, middleware and
+// routers of a siu web application. Several applications can run in the same
+// process, the package level functions operate on a default application.
+type Application struct {
 	environment config.TypedConfig
 	logger      interfaces.Logger
 
@@ -142,14 +163,40 @@ type context struct {
 	middleware    []interfaces.OrderedMiddleware
 	routers       []interfaces.Router
 	shutdownHooks []interfaces.ShutdownHook
-
-	store *sync.Map
-
-	server *gin.Engine
-}
-
-func newContext(environment config.TypedConfig, contextLogger interfaces.Logger, server *gin.Engine) *context {
-	ctx := &context{environment, contextLogger, make([]interfaces.InjectRegister, 0), make([]interfaces.AutoFactory, 0), make([]interfaces.OrderedMiddleware, 0), make([]interfaces.Router, 0), make([]interfaces.ShutdownHook, 0), &sync.Map{}, server}
+	processors    []inject.BeanPostProcessor
+
+	store      *sync.Map
+	container  *inject.Container
+	health     *health
+	discovered map[interface{}]struct{}
+
+	server     *gin.Engine
+	management *gin.Engine
+}
+
+// NewApplication returns an application with a container of its own.
+func NewApplication(environment config.TypedConfig, contextLogger interfaces.Logger, server *gin.Engine) *Application {
+	return newApplication(environment, contextLogger, server, inject.NewContainer())
+}
+
+// newApplication returns an application keeping its beans in the container, the default application keeps them in
+// inject.DefaultContainer so that the package level functions of inject find them.
+func newApplication(environment config.TypedConfig, contextLogger interfaces.Logger, server *gin.Engine, container *inject.Container) *Application {
+	ctx := &Application{
+		environment:   &config.DecryptEnvironment{Config: environment, Cipher: &containerCipher{container}},
+		logger:        contextLogger,
+		registers:     make([]interfaces.InjectRegister, 0),
+		auto:          make([]interfaces.AutoFactory, 0),
+		middleware:    make([]interfaces.OrderedMiddleware, 0),
+		routers:       make([]interfaces.Router, 0),
+		shutdownHooks: make([]interfaces.ShutdownHook, 0),
+		processors:    make([]inject.BeanPostProcessor, 0),
+		store:         &sync.Map{},
+		container:     container,
+		health:        &health{},
+		discovered:    make(map[interface{}]struct{}),
+		server:        server,
+	}
 	if leveledLogger, ok := contextLogger.(interfaces.LeveledLogger); ok {
 		common.SetLevel(leveledLogger.Level())
 	}
@@ -158,11 +205,16 @@ func newContext(environment config.TypedConfig, contextLogger interfaces.Logger,
 	}
 	ctx.Register(&buildinRegister{ctx})
 	ctx.AutoFactory(&autoconfig.AutoMysql{}, &autoconfig.AutoGorm{}, &autoconfig.AutoRedis{}, &autoconfig.AutoZookeeper{}, &autoconfig.AutoOss{}, &autoconfig.AutoCipher{})
-	ctx.Use(&middleware.MiddlewareRewrite{}, &middleware.MiddlewareAccess{}, &middleware.MiddlewareCROS{}, &middleware.MiddlewareErrorlog{}, &middleware.MiddlewareResource{}, &middleware.MiddlewareSession{}, &middleware.MiddlewareJwt{})
+	ctx.Use(&middleware.MiddlewareTLS{}, &middleware.MiddlewareRewrite{}, &middleware.MiddlewareAccess{}, &middleware.MiddlewareCROS{}, &middleware.MiddlewareErrorlog{}, &middleware.MiddlewareResource{}, &middleware.MiddlewareSession{}, &middleware.MiddlewareJwt{})
 	return ctx
 }
 
-func newEnvironmentContext(environment config.TypedConfig) *context {
+func NewEnvironmentApplication(environment config.TypedConfig) *Application {
+	return NewApplication(environment, newContextLogger(environment), nil)
+}
+
+// newContextLogger returns the logger configured by the logger.* keys of the environment.
+func newContextLogger(environment config.TypedConfig) interfaces.Logger {
 	logUse := environment.GetBoolOr(loggerUseEnvKey, true)
 	tag := environment.GetStringOr(loggerTagEnvKey, "[SIU]")
 	logLevel := logger.Parse(environment.GetStringOr(loggerLevelEnvKey, "info"))
@@ -171,14 +223,18 @@ func newEnvironmentContext(environment config.TypedConfig) *context {
 	filePath := environment.GetStringOr(loggerPathEnvKey, ".")
 	fileName := environment.GetStringOr(loggerFileEnvKey, "stdout")
 	maxFiles := environment.GetIntOr(loggerMaxFilesEnvKey, 30)
-	maxFileSize := environment.GetIntOr(loggerMaxFileSizesEnvKey, 200)
+	// a bare number of megabytes is kept for compatibility, sizes like 512KB or 1GB are accepted as well
+	maxFileSize := environment.GetByteSizeOr(loggerMaxFileSizesEnvKey, 200*logger.FileSizeM)
+	if size, ok := environment.GetInt64(loggerMaxFileSizesEnvKey); ok {
+		maxFileSize = size * logger.FileSizeM
+	}
 
 	var w io.Writer
 	cfg := &logger.RotateConfig{
 		Enable:      true,
 		Daily:       daily,
 		MaxFiles:    maxFiles,
-		MaxFileSize: int64(maxFileSize) * logger.FileSizeM,
+		MaxFileSize: maxFileSize,
 		FilePath:    filePath,
 		FileName:    fileName,
 	}
@@ -224,17 +280,15 @@ func newEnvironmentContext(environment config.TypedConfig) *context {
 		contextLogger = newBuildinLogger(logLevel, tag, w)
 		common.INFO("use buildin logger")
 	}
-
-	ctx := newContext(environment, contextLogger, nil)
-	return ctx
+	return contextLogger
 }
 
-func newDefaultContext() *context {
+func NewDefaultApplication() *Application {
 	environment := &config.ConfigurationEnvironment{}
-	return newEnvironmentContext(environment)
+	return NewEnvironmentApplication(environment)
 }
 
-func (c *context) banner() {
+func (c *Application) banner() {
 	if bannerFile, ok := c.environment.GetString("banner.file"); ok {
 		bannerBts, err := os.ReadFile(bannerFile)
 		if err != nil {
@@ -245,29 +299,33 @@ func (c *context) banner() {
 	c.logger.INFO(fmt.Sprintf(defaultBanner, VERSION))
 }
 
-func (c *context) DEBUG(format string, arr ...interface{}) {
+func (c *Application) DEBUG(format string, arr ...interface{}) {
 	c.logger.DEBUG(format, arr...)
 }
 
-func (c *context) INFO(format string, arr ...interface{}) {
+func (c *Application) INFO(format string, arr ...interface{}) {
 	c.logger.INFO(format, arr...)
 }
 
-func (c *context) WARN(format string, arr ...interface{}) {
+func (c *Application) WARN(format string, arr ...interface{}) {
 	c.logger.WARN(format, arr...)
 }
 
-func (c *context) ERROR(format string, arr ...interface{}) {
+func (c *Application) ERROR(format string, arr ...interface{}) {
 	c.logger.ERROR(format, arr...)
 }
 
 type beanRegister struct {
-	obj  interface{}
-	name string
-	typ  reflect.Type
+	obj        interface{}
+	name       string
+	typ        reflect.Type
+	conditions []inject.Condition
 }
 
 func (p *beanRegister) Named() map[string]interface{} {
+	if p.name == "" {
+		return nil
+	}
 	return map[string]interface{}{
 		p.name: p.obj,
 	}
@@ -283,39 +341,78 @@ func (p *beanRegister) Order() int {
 	return BeanRegisterOrder
 }
 
-func (c *context) RegisterBean(name string, typ reflect.Type, obj interface{}) {
-	c.registers = append(c.registers, &beanRegister{obj, name, typ})
+func (p *beanRegister) Conditions() []inject.Condition {
+	return p.conditions
 }
 
-func (c *context) GetBeanByName(name string) (interface{}, bool) {
-	return inject.GetNamed(name)
+// RegisterBean registers the object by name and by type, only when all the conditions match.
+func (c *Application) RegisterBean(name string, typ reflect.Type, obj interface{}, conditions ...inject.Condition) {
+	c.registers = append(c.registers, &beanRegister{obj, name, typ, conditions})
+}
+
+// Provide registers a constructor whose result is registered as a bean, see inject.Container.Provide.
+// The constructor is called on the first lookup of the bean, the constructors not called yet are called after
+// the auto factories are started, an error aborts the startup.
+func (c *Application) Provide(constructor interface{}, options ...inject.ProvideOption) {
+	if err := c.container.Provide(constructor, options...); err != nil {
+		panic(err)
+	}
 }
 
-func (c *context) GetBeanByType(typ reflect.Type) (interface{}, bool) {
-	return inject.GetTyped(typ)
+func (c *Application) GetBeanByName(name string) (interface{}, bool) {
+	return c.container.GetNamed(name)
 }
 
-func (c *context) Register(registers ...interfaces.InjectRegister) {
+func (c *Application) GetBeanByType(typ reflect.Type) (interface{}, bool) {
+	return c.container.GetTyped(typ)
+}
+
+// Container returns the bean container owned by the application.
+func (c *Application) Container() *inject.Container {
+	return c.container
+}
+
+func (c *Application) Register(registers ...interfaces.InjectRegister) {
 	c.registers = append(c.registers, registers...)
 }
 
-func (c *context) AutoFactory(auto ...interfaces.AutoFactory) {
+func (c *Application) AutoFactory(auto ...interfaces.AutoFactory) {
 	c.auto = append(c.auto, auto...)
 }
 
-func (c *context) Use(middleware ...interfaces.OrderedMiddleware) {
+func (c *Application) Use(middleware ...interfaces.OrderedMiddleware) {
 	c.middleware = append(c.middleware, middleware...)
 }
 
-func (c *context) Route(router ...interfaces.Router) {
+func (c *Application) Route(router ...interfaces.Router) {
 	c.routers = append(c.routers, router...)
 }
 
-func (c *context) Shutdown(shutdown ...interfaces.ShutdownHook) {
+func (c *Application) Shutdown(shutdown ...interfaces.ShutdownHook) {
 	c.shutdownHooks = append(c.shutdownHooks, shutdown...)
 }
 
-func (c *context) Forward(ctx *gin.Context, path string) {
+// process injects the component and returns the object returned by the post processors, which must still be a T.
+func process[T any](c *Application, resolver inject.ValueResolver, v T) T {
+	processed, err := c.container.Process(resolver, v)
+	if err != nil {
+		panic(err)
+	}
+	t, ok := processed.(T)
+	if !ok {
+		panic(fmt.Errorf("post processed object %T does not implement %s", processed, inject.TypeOf[T]()))
+	}
+	return t
+}
+
+// PostProcess adds the processors called for every bean, auto factory, middleware and router before and after its
+// Init, the object they return replaces the component. The processors are injected with the configuration before any
+// bean is registered.
+func (c *Application) PostProcess(processors ...inject.BeanPostProcessor) {
+	c.processors = append(c.processors, processors...)
+}
+
+func (c *Application) Forward(ctx *gin.Context, path string) {
 	url := ctx.Request.URL.Path
 	ctx.Request.URL.Path = path
 	ctx.Request.RequestURI = strings.Replace(ctx.Request.RequestURI, url, path, 1)
@@ -323,24 +420,28 @@ func (c *context) Forward(ctx *gin.Context, path string) {
 	ctx.Abort()
 }
 
-func (c *context) Get(key string) (interface{}, bool) {
+func (c *Application) Get(key string) (interface{}, bool) {
 	return c.store.Load(key)
 }
 
-func (c *context) Set(key string, value interface{}) {
+func (c *Application) Set(key string, value interface{}) {
 	c.store.Store(key, value)
 }
 
 type buildinRegister struct {
-	c *context
+	c *Application
 }
 
 func (p *buildinRegister) Named() map[string]interface{} {
-	return map[string]interface{}{
+	named := map[string]interface{}{
 		"environment": p.c.environment,
 		"logger":      p.c.logger,
 		"server":      p.c.server,
 	}
+	if p.c.management != nil {
+		named["management"] = p.c.management
+	}
+	return named
 }
 
 func (p *buildinRegister) Typed() map[reflect.Type]interface{} {
@@ -355,41 +456,60 @@ func (p *buildinRegister) Order() int {
 	return BuildinRegisterOrder
 }
 
-func (c *context) register(resolver inject.ValueResolver) {
+func (c *Application) register(resolver inject.ValueResolver) {
 	rs := interfaces.OrderSlice[interfaces.InjectRegister](c.registers)
 	sort.Sort(rs)
 	for _, register := range rs {
-		s := make(map[interface{}]struct{})
+		if !c.matches(resolver, register) {
+			continue
+		}
+		s := make(map[interface{}]interface{})
 		for k, v := range register.Named() {
-			if _, ok := inject.GetNamed(k); !ok {
-				if _, ok := s[v]; !ok && register.Order() != BuildinRegisterOrder {
-					err := inject.Inject(resolver, v)
-					if err != nil {
-						panic(err)
+			if !c.matches(resolver, v) {
+				continue
+			}
+			if _, ok := c.container.GetNamed(k); !ok {
+				bean, ok := s[v]
+				if !ok {
+					bean = v
+					if register.Order() != BuildinRegisterOrder {
+						var err error
+						if bean, err = c.container.Process(resolver, v); err != nil {
+							panic(err)
+						}
 					}
 				}
-				err := inject.RegisterNamed(k, v)
+				err := c.container.RegisterNamed(k, bean)
 				if err != nil {
 					panic(err)
 				}
-				s[v] = struct{}{}
+				c.discover(bean)
+				s[v] = bean
 			} else if register.Order() != BuildinRegisterOrder {
 				panic(fmt.Errorf("named object \"%s\" is already registered", k))
 			}
 		}
 		for k, v := range register.Typed() {
-			if _, ok := inject.GetTyped(k); !ok {
-				if _, ok := s[v]; !ok && register.Order() != BuildinRegisterOrder {
-					err := inject.Inject(resolver, v)
-					if err != nil {
-						panic(err)
+			if !c.matches(resolver, v) {
+				continue
+			}
+			if _, ok := c.container.GetTyped(k); !ok {
+				bean, ok := s[v]
+				if !ok {
+					bean = v
+					if register.Order() != BuildinRegisterOrder {
+						var err error
+						if bean, err = c.container.Process(resolver, v); err != nil {
+							panic(err)
+						}
 					}
 				}
-				err := inject.RegisterTyped(k, v)
+				err := c.container.RegisterTyped(k, bean)
 				if err != nil {
 					panic(err)
 				}
-				s[v] = struct{}{}
+				c.discover(bean)
+				s[v] = bean
 			} else if register.Order() != BuildinRegisterOrder {
 				panic(fmt.Errorf("typed object %s is already registered", k))
 			}
@@ -397,8 +517,256 @@ func (c *context) register(resolver inject.ValueResolver) {
 	}
 }
 
-func (c *context) Run() {
-	ctx.banner()
+// bootstrap starts the interfaces.BootstrapFactory factories once the configuration is validated and before the
+// beans are registered, so that all of them read the ENC(...) values decrypted by the cipher they register. They are
+// injected with the built-in beans only, and skipped when a register of the application declares one of their beans.
+func (c *Application) bootstrap(resolver inject.ValueResolver) []interfaces.AutoFactory {
+	declared := make(map[reflect.Type]struct{})
+	for _, register := range c.registers {
+		if register.Order() == BuildinRegisterOrder || !c.matches(resolver, register) {
+			continue
+		}
+		for typ := range register.Typed() {
+			declared[typ] = struct{}{}
+		}
+	}
+	buildin := inject.NewContainer()
+	register := &buildinRegister{c}
+	for k, v := range register.Named() {
+		if err := buildin.RegisterNamed(k, v); err != nil {
+			panic(err)
+		}
+	}
+	for k, v := range register.Typed() {
+		if err := buildin.RegisterTyped(k, v); err != nil {
+			panic(err)
+		}
+	}
+
+	fs := interfaces.OrderSlice[interfaces.AutoFactory](c.auto)
+	sort.Sort(fs)
+	started := make([]interfaces.AutoFactory, 0)
+	for _, a := range fs {
+		if _, ok := a.(interfaces.BootstrapFactory); !ok {
+			continue
+		}
+		if err := buildin.Inject(resolver, a); err != nil {
+			panic(err)
+		}
+		replaced := false
+		for typ := range a.Typed() {
+			if _, ok := declared[typ]; ok {
+				replaced = true
+			}
+		}
+		if replaced || !c.matches(resolver, a) || !a.Condition() {
+			common.DEBUG("%s is disabled", a.Name())
+			continue
+		}
+		c.start(a)
+		started = append(started, a)
+	}
+	return started
+}
+
+// start starts the auto factory and registers its beans.
+func (c *Application) start(a interfaces.AutoFactory) {
+	common.DEBUG("%s is starting", a.Name())
+	if err := a.OnStart(); err != nil {
+		common.ERROR("", err)
+		panic(err)
+	}
+	common.DEBUG("%s is start", a.Name())
+	c.discover(a)
+	for k, v := range a.Named() {
+		if err := c.container.RegisterNamed(k, v); err != nil {
+			panic(err)
+		}
+	}
+	for k, v := range a.Typed() {
+		if err := c.container.RegisterTyped(k, v); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// containerCipher decrypts with the interfaces.Cipher bean of the container, the values read before the bean is
+// registered can not be decrypted.
+type containerCipher struct {
+	container *inject.Container
+}
+
+func (p *containerCipher) Decrypt(s string) (string, error) {
+	cipher, ok := inject.Typed[interfaces.Cipher](p.container)
+	if !ok {
+		return "", fmt.Errorf("no cipher is available")
+	}
+	return cipher.Decrypt(s)
+}
+
+// provideLazy provides the beans declared by a lazy factory, the factory is started on the first lookup of any of
+// them and onStart is called once it is started. A factory which fails to start fails the lookups of its beans.
+func (c *Application) provideLazy(a interfaces.LazyFactory, onStart func()) error {
+	var once sync.Once
+	var startErr error
+	start := func() error {
+		once.Do(func() {
+			common.DEBUG("%s is starting", a.Name())
+			if startErr = a.OnStart(); startErr != nil {
+				common.ERROR("", startErr)
+				return
+			}
+			common.DEBUG("%s is start", a.Name())
+			onStart()
+		})
+		return startErr
+	}
+	types, names := a.Declared()
+	for _, typ := range types {
+		typ := typ
+		constructor := lazyConstructor(typ, func() (interface{}, error) {
+			if err := start(); err != nil {
+				return nil, err
+			}
+			return a.Typed()[typ], nil
+		})
+		if err := c.container.Provide(constructor, inject.WithLazy()); err != nil {
+			return err
+		}
+	}
+	keys := make([]string, 0, len(names))
+	for name := range names {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	for _, name := range keys {
+		name := name
+		constructor := lazyConstructor(names[name], func() (interface{}, error) {
+			if err := start(); err != nil {
+				return nil, err
+			}
+			return a.Named()[name], nil
+		})
+		if err := c.container.Provide(constructor, inject.WithNameOnly(name), inject.WithLazy()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lazyConstructor returns a constructor of the signature func() (typ, error) returning the result of get.
+func lazyConstructor(typ reflect.Type, get func() (interface{}, error)) interface{} {
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+	fn := reflect.FuncOf(nil, []reflect.Type{typ, errType}, false)
+	return reflect.MakeFunc(fn, func([]reflect.Value) []reflect.Value {
+		bean := reflect.New(typ).Elem()
+		err := reflect.New(errType).Elem()
+		v, e := get()
+		if e == nil && v != nil && !reflect.TypeOf(v).AssignableTo(typ) {
+			e = fmt.Errorf("object of type %T is not assignable to %s", v, typ)
+		}
+		if e != nil {
+			err.Set(reflect.ValueOf(e))
+		} else if v != nil {
+			bean.Set(reflect.ValueOf(v))
+		}
+		return []reflect.Value{bean, err}
+	}).Interface()
+}
+
+// matches evaluates the conditions of a component implementing inject.Conditional, the other components always
+// match.
+func (c *Application) matches(resolver inject.ValueResolver, v interface{}) bool {
+	conditional, ok := v.(inject.Conditional)
+	if !ok {
+		return true
+	}
+	ok, condition := c.container.Matches(resolver, conditional.Conditions()...)
+	if !ok {
+		common.DEBUG("%s is skipped, %s does not match", reflect.TypeOf(v), condition)
+	}
+	return ok
+}
+
+// validate binds the configuration of all the components before any of them is injected or started,
+// every invalid key is reported at once. The cipher is not started yet, the ENC(...) values are validated once
+// they are decrypted, when the components are injected.
+func (c *Application) validate() {
+	var environment config.Config = c.environment
+	if decrypt, ok := c.environment.(*config.DecryptEnvironment); ok && decrypt.Config != nil {
+		environment = decrypt.Config
+	}
+	resolver := &inject.ConfigResolver{C: environment}
+	objs := make([]interface{}, 0)
+	for _, register := range c.registers {
+		if register.Order() == BuildinRegisterOrder {
+			continue
+		}
+		for _, v := range register.Named() {
+			objs = append(objs, v)
+		}
+		for _, v := range register.Typed() {
+			objs = append(objs, v)
+		}
+	}
+	for _, a := range c.auto {
+		objs = append(objs, a)
+	}
+	for _, m := range c.middleware {
+		objs = append(objs, m)
+	}
+	for _, router := range c.routers {
+		objs = append(objs, router)
+	}
+	errs := &config.ValidationError{}
+	for _, obj := range objs {
+		errs.Append(inject.Validate(resolver, obj))
+	}
+	if err := errs.Err(); err != nil {
+		c.logger.ERROR("%v", err)
+		panic(err)
+	}
+}
+
+// discover collects the health indicators and configuration change listeners among the beans.
+func (c *Application) discover(v interface{}) {
+	if _, ok := c.discovered[v]; ok {
+		return
+	}
+	c.discovered[v] = struct{}{}
+	if indicator, ok := v.(interfaces.HealthIndicator); ok {
+		c.health.add(indicator)
+	}
+	if listener, ok := v.(config.ConfigChangeListener); ok {
+		config.AddChangeListener(listener)
+	}
+}
+
+// discoverProvided collects the health indicators and configuration change listeners among the provided singletons
+// created so far.
+func (c *Application) discoverProvided() {
+	for _, bean := range c.container.Singletons() {
+		if v := reflect.ValueOf(bean); v.Kind() == reflect.Ptr && !v.IsNil() {
+			c.discover(bean)
+		}
+	}
+}
+
+// OnConfigChange applies a reloaded logger.level to the loggers.
+func (c *Application) OnConfigChange(keys []string) {
+	if !config.ContainsKey(keys, loggerLevelEnvKey) {
+		return
+	}
+	logLevel := logger.Parse(c.environment.GetStringOr(loggerLevelEnvKey, "info"))
+	common.SetLevel(logLevel)
+	if mutableLogger, ok := c.logger.(interfaces.MutableLeveledLogger); ok {
+		mutableLogger.SetLevel(logLevel)
+	}
+	c.logger.INFO("logger level changed to %s", c.environment.GetStringOr(loggerLevelEnvKey, "info"))
+}
+
+func (c *Application) Run() {
+	c.banner()
 
 	if c.server == nil {
 		mode := c.environment.GetStringOr("server.mode", "release")
@@ -406,81 +774,133 @@ func (c *context) Run() {
 		c.server = gin.New()
 		c.server.SetTrustedProxies(nil)
 	}
+	c.management = c.newManagement()
 
 	resolver := &inject.ConfigResolver{C: c.environment}
+	c.validate()
+	started := c.bootstrap(resolver)
+	var startedLock sync.Mutex
+	c.container.SetAllowCircularReferences(c.environment.GetBoolOr(InjectAllowCircularReferencesKey, true))
+	for _, processor := range c.processors {
+		if err := c.container.Inject(resolver, processor); err != nil {
+			panic(err)
+		}
+	}
+	c.container.AddPostProcessor(c.processors...)
 
 	c.register(resolver)
+	if err := c.container.ActivateProviders(resolver); err != nil {
+		c.logger.ERROR("%v", err)
+		panic(err)
+	}
 
 	fs := interfaces.OrderSlice[interfaces.AutoFactory](c.auto)
 	sort.Sort(fs)
 	for _, a := range fs {
-		err := inject.Inject(resolver, a)
-		if err != nil {
-			panic(err)
+		if _, ok := a.(interfaces.BootstrapFactory); ok {
+			continue
 		}
-		if a.Condition() {
-			common.DEBUG("%s is starting", a.Name())
-			err := a.OnStart()
-			if err != nil {
-				common.ERROR("", err)
-				panic(err)
-			}
-			common.DEBUG("%s is start", a.Name())
-			for k, v := range a.Named() {
-				err := inject.RegisterNamed(k, v)
-				if err != nil {
-					panic(err)
-				}
-			}
-			for k, v := range a.Typed() {
-				err := inject.RegisterTyped(k, v)
+		a := process(c, resolver, a)
+		if c.matches(resolver, a) && a.Condition() {
+			if lazy, ok := a.(interfaces.LazyFactory); ok && lazy.Lazy() {
+				err := c.provideLazy(lazy, func() {
+					startedLock.Lock()
+					started = append(started, a)
+					startedLock.Unlock()
+				})
 				if err != nil {
 					panic(err)
 				}
+				c.discover(a)
+				common.DEBUG("%s is lazy", a.Name())
+				continue
 			}
+			c.start(a)
+			startedLock.Lock()
+			started = append(started, a)
+			startedLock.Unlock()
 		} else {
 			common.DEBUG("%s is disabled", a.Name())
 		}
 	}
 
+	// the providers conditional on the beans of the auto factories
+	if err := c.container.ActivateProviders(resolver); err != nil {
+		c.logger.ERROR("%v", err)
+		panic(err)
+	}
+	if err := c.container.ResolveProviders(); err != nil {
+		c.logger.ERROR("%v", err)
+		panic(err)
+	}
+	c.discoverProvided()
+
+	c.logger.DEBUG("Effective configuration:\n%s", config.FormatDump(config.DumpOf(c.environment)))
+
 	defer func() {
-		for i := len(fs) - 1; i >= 0; i-- {
-			if fs[i].Condition() {
-				common.DEBUG("%s is stoping", fs[i].Name())
-				err := fs[i].OnStop()
-				if err != nil {
-					common.ERROR("", err)
-				}
-				common.DEBUG("%s is stop", fs[i].Name())
+		startedLock.Lock()
+		defer startedLock.Unlock()
+		for i := len(started) - 1; i >= 0; i-- {
+			common.DEBUG("%s is stoping", started[i].Name())
+			err := started[i].OnStop()
+			if err != nil {
+				common.ERROR("", err)
 			}
+			common.DEBUG("%s is stop", started[i].Name())
 		}
 		c.logger.INFO("Server is stop")
 	}()
 
+	var managementBase *gin.RouterGroup
+	if c.management != nil {
+		managementBase = c.management.Group(c.environment.GetStringOr(ManagementPrefixKey, ""))
+		c.routeManagement(managementBase)
+	}
+
+	if !c.environment.GetBoolOr(HealthDisableKey, false) {
+		c.health.timeout = c.environment.GetDurationOr(HealthTimeoutKey, 3*time.Second)
+		if managementBase != nil {
+			c.health.route(managementBase, c.environment.GetStringOr(HealthPrefixKey, HealthDefaultPrefix))
+		} else {
+			c.health.route(c.server, c.environment.GetStringOr(HealthPrefixKey, HealthDefaultPrefix))
+		}
+	}
+
+	c.server.Use(c.requestScope())
+
 	ms := interfaces.OrderSlice[interfaces.OrderedMiddleware](c.middleware)
 	sort.Sort(ms)
 	for _, m := range ms {
-		err := inject.Inject(resolver, m)
-		if err != nil {
-			panic(err)
-		}
+		m := process(c, resolver, m)
 		if m.Condition() {
 			c.server.Use(m.Function())
+			c.discover(m)
 		} else {
 			common.DEBUG("%s is disabled", reflect.TypeOf(m))
 		}
 	}
-	for _, router := range c.routers {
-		err := inject.Inject(resolver, router)
-		if err != nil {
-			panic(err)
-		}
+	for i, router := range c.routers {
+		c.routers[i] = process(c, resolver, router)
+		c.discover(c.routers[i])
+	}
+	if _, ok := c.environment.Get(config.WatchKey); ok && !c.environment.GetBoolOr(config.WatchDisableKey, false) {
+		config.AddChangeListener(c)
+		stopWatch := config.Watch(c.environment.GetDurationOr(config.WatchIntervalKey, 5*time.Second))
+		defer stopWatch()
+		defer config.RemoveChangeListener(c)
 	}
 	prefix := c.environment.GetStringOr("server.prefix", "")
 	base := c.server.Group(prefix)
 	for _, router := range c.routers {
 		rs := router.Router()
 		group := base.Group("")
+		if lr, ok := router.(interfaces.ListenerRouter); ok && lr.Listener() == interfaces.ManagementListener {
+			if managementBase != nil {
+				group = managementBase.Group("")
+			} else {
+				common.WARN("management listener is not configured, %s is served by the server listener", reflect.TypeOf(router))
+			}
+		}
 		if mr, ok := router.(interfaces.MiddlewareRouter); ok {
 			if ms := mr.Middleware(); ms != nil {
 				group.Use(ms...)
@@ -494,23 +914,100 @@ func (c *context) Run() {
 			}
 		}
 	}
+	tlsConfig, reloader, err := newTLSConfig(c.environment)
+	if err != nil {
+		panic(err)
+	}
+	if tlsConfig != nil {
+		stopReload := make(chan struct{})
+		defer close(stopReload)
+		go reloader.watch(c.environment.GetDurationOr(TLSReloadIntervalKey, 10*time.Second), stopReload)
+		c.logger.INFO("TLS is enabled")
+	}
 	ip := c.environment.GetStringOr("server.ip", "0.0.0.0")
 	port := c.environment.GetStringOr("server.port", "8080")
-	go func() {
-		err := c.server.Run(fmt.Sprintf("%s:%s", ip, port))
-		if err != nil {
-			panic(err)
+	servers := []*http.Server{c.serve(c.server, "tcp", fmt.Sprintf("%s:%s", ip, port), tlsConfig)}
+	if socket, ok := c.environment.GetString(ServerUnixSocketKey); ok {
+		servers = append(servers, c.serve(c.server, "unix", socket, nil))
+	}
+	if c.management != nil {
+		if managementPort, ok := c.environment.GetString(ManagementPortKey); ok {
+			managementIP := c.environment.GetStringOr(ManagementIPKey, ip)
+			servers = append(servers, c.serve(c.management, "tcp", fmt.Sprintf("%s:%s", managementIP, managementPort), nil))
 		}
-	}()
-	c.logger.INFO("Listening on: %s:%s", ip, port)
+		if socket, ok := c.environment.GetString(ManagementUnixSocketKey); ok {
+			servers = append(servers, c.serve(c.management, "unix", socket, nil))
+		}
+	}
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
+	c.health.markDown()
+	c.logger.INFO("Server stoping...")
+	if shutdownDelay := c.environment.GetDurationOr("server.shutdown-delay", 0); shutdownDelay > 0 {
+		// keep serving while load balancers observe the failing readiness probe
+		time.Sleep(shutdownDelay)
+	}
+	shutdownTimeout := c.environment.GetDurationOr("server.shutdown-timeout", 30*time.Second)
+	if err := drain(servers, shutdownTimeout); err != nil {
+		c.logger.ERROR("Server forced to shutdown, in-flight requests are dropped: %v", err)
+	} else {
+		common.DEBUG("in-flight requests are drained")
+	}
 	hs := interfaces.OrderSlice[interfaces.ShutdownHook](c.shutdownHooks)
 	sort.Sort(hs)
 	for i := len(hs) - 1; i >= 0; i-- {
 		hs[i].Function()()
 		common.DEBUG("%s is stop", hs[i].Name())
 	}
-	c.logger.INFO("Server stoping...")
+	if err := c.container.Destroy(c.environment.GetDurationOr(InjectDestroyTimeoutKey, 10*time.Second)); err != nil {
+		c.logger.ERROR("%v", err)
+	}
+}
+
+// drain shuts the servers down and waits for their in-flight requests until the timeout, the servers which are
+// not drained in time are closed with their connections.
+func drain(servers []*http.Server, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	errs := make(chan error, len(servers))
+	wg := &sync.WaitGroup{}
+	for _, httpServer := range servers {
+		wg.Add(1)
+		go func(httpServer *http.Server) {
+			defer wg.Done()
+			if err := httpServer.Shutdown(ctx); err != nil {
+				httpServer.Close()
+				errs <- err
+			}
+		}(httpServer)
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// serve listens on the address and serves the handler in the background until the returned server is shut down.
+func (c *Application) serve(handler http.Handler, network string, address string, tlsConfig *tls.Config) *http.Server {
+	if network == "unix" {
+		if err := os.Remove(address); err != nil && !os.IsNotExist(err) {
+			panic(err)
+		}
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		panic(err)
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	httpServer := &http.Server{Handler: handler}
+	go func() {
+		err := httpServer.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			panic(err)
+		}
+	}()
+	c.logger.INFO("Listening on: %s:%s", network, address)
+	return httpServer
 }
@@ -0,0 +1,191 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package siu
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stella-go/logger"
+	"github.com/stella-go/siu/inject"
+	"github.com/stella-go/siu/interfaces"
+)
+
+func TestDrain(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		timeout time.Duration
+		drained bool
+	}{
+		{"drained", 200 * time.Millisecond, 5 * time.Second, true},
+		{"timeout", 5 * time.Second, 100 * time.Millisecond, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arrived := make(chan struct{})
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(arrived)
+				select {
+				case <-time.After(tt.delay):
+				case <-release:
+				}
+				io.WriteString(w, "done")
+			}))
+			defer server.Close()
+			defer close(release)
+
+			responses := make(chan string, 1)
+			go func() {
+				resp, err := http.Get(server.URL)
+				if err != nil {
+					responses <- err.Error()
+					return
+				}
+				defer resp.Body.Close()
+				body, _ := io.ReadAll(resp.Body)
+				responses <- string(body)
+			}()
+			<-arrived
+
+			start := time.Now()
+			err := drain([]*http.Server{server.Config}, tt.timeout)
+			if (err == nil) != tt.drained {
+				t.Fatal(err)
+			}
+			if elapsed := time.Since(start); elapsed >= 2*time.Second {
+				t.Fatal(elapsed)
+			}
+			if body := <-responses; (body == "done") != tt.drained {
+				t.Fatal(body)
+			}
+			if _, err := http.Get(server.URL); err == nil {
+				t.Fatal("server is still listening")
+			}
+		})
+	}
+}
+
+type clientSettings struct {
+	Endpoint string `config:"required='true',regex='^https?://'"`
+	Retries  int    `config:"min='0',max='5'"`
+}
+
+type clientRouter struct {
+	Settings *clientSettings `@siu:"value='${client}'"`
+}
+
+func (*clientRouter) Router() map[string]gin.HandlerFunc {
+	return nil
+}
+
+// countingCipher counts the values it decrypts.
+type countingCipher struct {
+	calls int
+}
+
+func (p *countingCipher) Decrypt(s string) (string, error) {
+	p.calls++
+	return s, nil
+}
+
+func TestValidate(t *testing.T) {
+	cipher := &countingCipher{}
+	container := inject.NewContainer()
+	container.RegisterTyped(inject.TypeOf[interfaces.Cipher](), cipher)
+	c := newApplication(typedConfig(map[string]interface{}{"client.endpoint": "ENC(abc)", "client.retries": 9}), newBuildinLogger(logger.ErrorLevel, "[TEST]", io.Discard), gin.New(), container)
+	c.Route(&clientRouter{})
+	err := func() (err error) {
+		defer func() {
+			err = fmt.Errorf("%v", recover())
+		}()
+		c.validate()
+		return nil
+	}()
+	if !strings.Contains(err.Error(), "client.retries") || strings.Contains(err.Error(), "client.endpoint") {
+		t.Fatal(err)
+	}
+	if cipher.calls != 0 {
+		t.Fatal("validated before the cipher is started", cipher.calls)
+	}
+}
+
+type plainRouter struct{}
+
+func (*plainRouter) Router() map[string]gin.HandlerFunc {
+	return nil
+}
+
+// replacing replaces the routers with its result.
+type replacing struct {
+	result interface{}
+}
+
+func (*replacing) BeforeInit(bean interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p *replacing) AfterInit(bean interface{}) (interface{}, error) {
+	if _, ok := bean.(interfaces.Router); ok {
+		return p.result, nil
+	}
+	return nil, nil
+}
+
+func TestProcess(t *testing.T) {
+	replacement := &plainRouter{}
+	tests := []struct {
+		name   string
+		result interface{}
+		err    string
+	}{
+		{"kept", nil, ""},
+		{"replaced", replacement, ""},
+		{"not a router", &countingCipher{}, "post processed object *siu.countingCipher does not implement interfaces.Router"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewApplication(typedConfig(map[string]interface{}{}), newBuildinLogger(logger.ErrorLevel, "[TEST]", io.Discard), gin.New())
+			c.container.AddPostProcessor(&replacing{result: tt.result})
+			router := &plainRouter{}
+			var processed interfaces.Router
+			err := func() (err error) {
+				defer func() {
+					if r := recover(); r != nil {
+						err = fmt.Errorf("%v", r)
+					}
+				}()
+				processed = process[interfaces.Router](c, &inject.NopValueResolver{}, router)
+				return nil
+			}()
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err != nil || (tt.result == nil && processed != router) || (tt.result != nil && processed != tt.result) {
+				t.Fatal(processed, err)
+			}
+		})
+	}
+}
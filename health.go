@@ -0,0 +1,116 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package siu
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stella-go/siu/interfaces"
+)
+
+const (
+	HealthDisableKey = "server.health.disable"
+	HealthPrefixKey  = "server.health.prefix"
+	HealthTimeoutKey = "server.health.timeout"
+
+	HealthDefaultPrefix = "/health"
+
+	HealthStatusUp   = "UP"
+	HealthStatusDown = "DOWN"
+)
+
+type HealthComponent struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type HealthReport struct {
+	Status     string                      `json:"status"`
+	Components map[string]*HealthComponent `json:"components,omitempty"`
+}
+
+type health struct {
+	indicators []interfaces.HealthIndicator
+	timeout    time.Duration
+	down       int32
+}
+
+func (h *health) add(indicators ...interfaces.HealthIndicator) {
+	h.indicators = append(h.indicators, indicators...)
+}
+
+// markDown makes the readiness probe fail, it is called as soon as shutdown begins.
+func (h *health) markDown() {
+	atomic.StoreInt32(&h.down, 1)
+}
+
+func (h *health) isDown() bool {
+	return atomic.LoadInt32(&h.down) == 1
+}
+
+func (h *health) check() *HealthReport {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	report := &HealthReport{Status: HealthStatusUp, Components: make(map[string]*HealthComponent)}
+	components := make([]*HealthComponent, len(h.indicators))
+	wg := &sync.WaitGroup{}
+	for i, indicator := range h.indicators {
+		wg.Add(1)
+		go func(i int, indicator interfaces.HealthIndicator) {
+			defer wg.Done()
+			if err := indicator.Health(ctx); err != nil {
+				components[i] = &HealthComponent{Status: HealthStatusDown, Error: err.Error()}
+			} else {
+				components[i] = &HealthComponent{Status: HealthStatusUp}
+			}
+		}(i, indicator)
+	}
+	wg.Wait()
+	for i, indicator := range h.indicators {
+		report.Components[indicator.Name()] = components[i]
+		if components[i].Status != HealthStatusUp {
+			report.Status = HealthStatusDown
+		}
+	}
+	if h.isDown() {
+		report.Status = HealthStatusDown
+	}
+	return report
+}
+
+func (h *health) live(c *gin.Context) {
+	c.JSON(http.StatusOK, &HealthReport{Status: HealthStatusUp})
+}
+
+func (h *health) ready(c *gin.Context) {
+	report := h.check()
+	if report.Status != HealthStatusUp {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *health) route(server gin.IRoutes, prefix string) {
+	server.GET(path.Join(prefix, "live"), h.live)
+	server.GET(path.Join(prefix, "ready"), h.ready)
+}
@@ -0,0 +1,116 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package siu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stella-go/logger"
+	"github.com/stella-go/siu/inject"
+	"github.com/stella-go/siu/interfaces"
+)
+
+type indicator struct {
+	name string
+	err  error
+	wait bool
+}
+
+func (i *indicator) Name() string {
+	return i.name
+}
+
+func (i *indicator) Health(ctx context.Context) error {
+	if i.wait {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return i.err
+}
+
+func TestHealth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		indicators []interfaces.HealthIndicator
+		down       bool
+		status     int
+		components map[string]string
+	}{
+		{"empty", nil, false, http.StatusOK, map[string]string{}},
+		{"up", []interfaces.HealthIndicator{&indicator{name: "db"}, &indicator{name: "redis"}}, false, http.StatusOK, map[string]string{"db": HealthStatusUp, "redis": HealthStatusUp}},
+		{"down", []interfaces.HealthIndicator{&indicator{name: "db"}, &indicator{name: "redis", err: fmt.Errorf("refused")}}, false, http.StatusServiceUnavailable, map[string]string{"db": HealthStatusUp, "redis": HealthStatusDown}},
+		{"timeout", []interfaces.HealthIndicator{&indicator{name: "oss", wait: true}}, false, http.StatusServiceUnavailable, map[string]string{"oss": HealthStatusDown}},
+		{"shutdown", []interfaces.HealthIndicator{&indicator{name: "db"}}, true, http.StatusServiceUnavailable, map[string]string{"db": HealthStatusUp}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &health{timeout: 50 * time.Millisecond}
+			h.add(tt.indicators...)
+			if tt.down {
+				h.markDown()
+			}
+			engine := gin.New()
+			h.route(engine, HealthDefaultPrefix)
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+			if w.Code != tt.status {
+				t.Fatal(w.Code, w.Body.String())
+			}
+			report := &HealthReport{}
+			if err := json.Unmarshal(w.Body.Bytes(), report); err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Components) != len(tt.components) {
+				t.Fatal(report.Components)
+			}
+			for name, status := range tt.components {
+				if report.Components[name] == nil || report.Components[name].Status != status {
+					t.Fatal(name, report.Components[name])
+				}
+			}
+
+			w = httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+			if w.Code != http.StatusOK {
+				t.Fatal(w.Code)
+			}
+		})
+	}
+}
+
+func TestDiscoverProvided(t *testing.T) {
+	c := NewApplication(typedConfig(map[string]interface{}{}), newBuildinLogger(logger.ErrorLevel, "[TEST]", io.Discard), gin.New())
+	provided := &indicator{name: "provided"}
+	c.container.Provide(func() *indicator { return provided })
+	c.container.Provide(func() interfaces.HealthIndicator { return &indicator{name: "lazy"} }, inject.WithLazy())
+	if err := c.container.ResolveProviders(); err != nil {
+		t.Fatal(err)
+	}
+	c.discoverProvided()
+	c.discoverProvided()
+	if len(c.health.indicators) != 1 || c.health.indicators[0] != provided {
+		t.Fatal(c.health.indicators)
+	}
+}
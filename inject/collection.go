@@ -0,0 +1,183 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/stella-go/siu/common"
+	"github.com/stella-go/siu/interfaces"
+)
+
+var orderType = reflect.TypeOf((*interfaces.Order)(nil)).Elem()
+
+// isBeanCollection reports whether t collects the beans assignable to its element type, e.g. []EventHandler or
+// map[string]Store, the element type is an interface with methods or a pointer.
+func isBeanCollection(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return false
+		}
+	default:
+		return false
+	}
+	elem := t.Elem()
+	return (elem.Kind() == reflect.Interface && elem.NumMethod() > 0) || elem.Kind() == reflect.Ptr
+}
+
+type collected struct {
+	name  string
+	value reflect.Value
+	order int
+}
+
+// resolveCollection returns the beans assignable to the element type of typ sorted by interfaces.Order, the
+// beans without order come last. The keys of a map are the bean names, or the type names of the beans
+// registered by type only. The objects being injected are not collected, nor the lazy singletons which have not
+// been looked up yet.
+func (c *Container) resolveCollection(typ reflect.Type, visited *visit) (reflect.Value, error) {
+	elem := typ.Elem()
+	c.lock.Lock()
+	providers := append([]*provider{}, c.providerList...)
+	c.lock.Unlock()
+	for _, p := range providers {
+		if p.scope == ScopeSingleton && !p.lazy && maybeAssignable(p.typ, elem) {
+			if _, err := c.callProvider(p, nil); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+	}
+
+	excluded := make(map[interface{}]struct{})
+	for _, v := range visited.objects {
+		excluded[identity(v)] = struct{}{}
+	}
+	seen := make(map[interface{}]struct{})
+	beans := make([]*collected, 0)
+	add := func(name string, v reflect.Value) {
+		v = unwrap(v)
+		if !v.IsValid() || !v.Type().AssignableTo(elem) || (v.Kind() == reflect.Ptr && v.IsNil()) {
+			return
+		}
+		id := identity(v)
+		if _, ok := excluded[id]; ok {
+			return
+		}
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+		order := math.MaxInt
+		if v.Type().Implements(orderType) {
+			order = v.Interface().(interfaces.Order).Order()
+		}
+		beans = append(beans, &collected{name: name, value: v, order: order})
+	}
+	c.named.Range(func(k, v interface{}) bool {
+		add(k.(string), v.(reflect.Value))
+		return true
+	})
+	c.typed.Range(func(k, v interface{}) bool {
+		add(v.(reflect.Value).Type().String(), v.(reflect.Value))
+		return true
+	})
+	for _, p := range providers {
+		if p.scope == ScopePrototype && maybeAssignable(p.typ, elem) {
+			v, err := c.callProvider(p, nil)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			add(p.String(), v)
+		}
+	}
+	sort.SliceStable(beans, func(i, j int) bool {
+		if beans[i].order != beans[j].order {
+			return beans[i].order < beans[j].order
+		}
+		return beans[i].name < beans[j].name
+	})
+
+	if typ.Kind() == reflect.Map {
+		m := reflect.MakeMapWithSize(typ, len(beans))
+		for _, b := range beans {
+			m.SetMapIndex(reflect.ValueOf(b.name).Convert(typ.Key()), b.value)
+		}
+		common.DEBUG("Collect %d objects into %s", len(beans), typ)
+		return m, nil
+	}
+	s := reflect.MakeSlice(typ, 0, len(beans))
+	for _, b := range beans {
+		s = reflect.Append(s, b.value)
+	}
+	common.DEBUG("Collect %d objects into %s", len(beans), typ)
+	return s, nil
+}
+
+// maybeAssignable reports whether the result of a provider of type typ may be assignable to elem, the dynamic
+// type of an interface result is only known once it is created.
+func maybeAssignable(typ reflect.Type, elem reflect.Type) bool {
+	return typ.AssignableTo(elem) || typ.Kind() == reflect.Interface
+}
+
+type pointerIdentity struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// identity returns a comparable identity of the bean, a bean registered by name and by type is collected once.
+func identity(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Interface {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return pointerIdentity{typ: v.Type(), ptr: v.Pointer()}
+	}
+	if isComparable(v) {
+		return v.Interface()
+	}
+	return v
+}
+
+// isComparable reports whether the value can be compared with ==, a struct or an array of interfaces is comparable
+// only when the dynamic values are.
+func isComparable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface:
+		return v.IsNil() || isComparable(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isComparable(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isComparable(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return v.Type().Comparable()
+}
@@ -0,0 +1,194 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/stella-go/siu/config"
+)
+
+// Condition decides whether a bean, a provider or an auto factory is registered.
+type Condition interface {
+	Matches(c *Container, r ValueResolver) bool
+	String() string
+}
+
+// Conditional is implemented by the beans, registers and auto factories which are registered only when all their
+// conditions match.
+type Conditional interface {
+	Conditions() []Condition
+}
+
+// Matches reports whether all the conditions match, it returns the first condition which does not.
+func (c *Container) Matches(r ValueResolver, conditions ...Condition) (bool, Condition) {
+	for _, condition := range conditions {
+		if condition != nil && !condition.Matches(c, r) {
+			return false, condition
+		}
+	}
+	return true, nil
+}
+
+type onProperty struct {
+	key    string
+	values []string
+}
+
+// OnProperty matches when the configuration key is set to one of the values, or without values when the key is
+// set to anything but false.
+func OnProperty(key string, values ...string) Condition {
+	return &onProperty{key: key, values: values}
+}
+
+func (p *onProperty) Matches(_ *Container, r ValueResolver) bool {
+	if r == nil {
+		return false
+	}
+	v, ok := r.Resolve(p.key)
+	if !ok {
+		return false
+	}
+	value := fmt.Sprintf("%v", v)
+	if len(p.values) == 0 {
+		return !strings.EqualFold(value, "false")
+	}
+	for _, expected := range p.values {
+		if strings.EqualFold(value, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *onProperty) String() string {
+	if len(p.values) == 0 {
+		return fmt.Sprintf("on-property %s", p.key)
+	}
+	return fmt.Sprintf("on-property %s=%s", p.key, strings.Join(p.values, "|"))
+}
+
+type onBean struct {
+	typ     reflect.Type
+	name    string
+	missing bool
+}
+
+// OnBean matches when a bean assignable to the type is registered or provided.
+func OnBean(typ reflect.Type) Condition {
+	return &onBean{typ: typ}
+}
+
+// OnBeanNamed matches when a bean of the name is registered or provided.
+func OnBeanNamed(name string) Condition {
+	return &onBean{name: name}
+}
+
+// OnMissingBean matches when no bean assignable to the type is registered or provided, it lets the users replace
+// the beans of the auto factories.
+func OnMissingBean(typ reflect.Type) Condition {
+	return &onBean{typ: typ, missing: true}
+}
+
+// OnMissingBeanNamed matches when no bean of the name is registered or provided.
+func OnMissingBeanNamed(name string) Condition {
+	return &onBean{name: name, missing: true}
+}
+
+func (p *onBean) Matches(c *Container, _ ValueResolver) bool {
+	if p.typ != nil {
+		return c.hasTyped(p.typ) != p.missing
+	}
+	return c.hasNamed(p.name) != p.missing
+}
+
+func (p *onBean) String() string {
+	condition := "on-bean"
+	if p.missing {
+		condition = "on-missing-bean"
+	}
+	if p.typ != nil {
+		return fmt.Sprintf("%s %s", condition, p.typ)
+	}
+	return fmt.Sprintf("%s \"%s\"", condition, p.name)
+}
+
+type onProfile struct {
+	profiles []string
+}
+
+// OnProfile matches when one of the profiles is active, a profile like !prod matches when prod is not active.
+func OnProfile(profiles ...string) Condition {
+	return &onProfile{profiles: profiles}
+}
+
+func (p *onProfile) Matches(_ *Container, _ ValueResolver) bool {
+	active := make(map[string]struct{})
+	for _, profile := range config.Profiles() {
+		active[profile] = struct{}{}
+	}
+	for _, profile := range p.profiles {
+		if strings.HasPrefix(profile, "!") {
+			if _, ok := active[profile[1:]]; !ok {
+				return true
+			}
+		} else if _, ok := active[profile]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *onProfile) String() string {
+	return fmt.Sprintf("on-profile %s", strings.Join(p.profiles, ","))
+}
+
+// hasTyped reports whether a bean assignable to the type is registered or provided.
+func (c *Container) hasTyped(typ reflect.Type) bool {
+	if _, ok := c.typed.Load(typ); ok {
+		return true
+	}
+	if _, ok := c.providers.Load(typ); ok {
+		return true
+	}
+	found := false
+	assignable := func(_, v interface{}) bool {
+		value := unwrap(v.(reflect.Value))
+		found = value.IsValid() && value.Type().AssignableTo(typ)
+		return !found
+	}
+	c.typed.Range(assignable)
+	if !found {
+		c.named.Range(assignable)
+	}
+	if !found {
+		c.providers.Range(func(_, v interface{}) bool {
+			found = v.(*provider).typ.AssignableTo(typ)
+			return !found
+		})
+	}
+	return found
+}
+
+// hasNamed reports whether a bean of the name is registered or provided.
+func (c *Container) hasNamed(name string) bool {
+	if _, ok := c.named.Load(name); ok {
+		return true
+	}
+	_, ok := c.namedProviders.Load(name)
+	return ok
+}
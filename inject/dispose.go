@@ -0,0 +1,99 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/stella-go/siu/common"
+)
+
+type Disposable interface {
+	// run when the container is destroyed
+	Destroy() error
+}
+
+var disposableType = reflect.TypeOf((*Disposable)(nil)).Elem()
+
+// track keeps the disposable singletons registered or provided by the container in the order of their creation,
+// an object registered by name and by type is kept once.
+func (c *Container) track(v reflect.Value) {
+	v = unwrap(v)
+	if !v.IsValid() || ((v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()) {
+		return
+	}
+	if !v.Type().Implements(disposableType) {
+		return
+	}
+	id := identity(v)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.tracked == nil {
+		c.tracked = make(map[interface{}]struct{})
+	}
+	if _, ok := c.tracked[id]; ok {
+		return
+	}
+	c.tracked[id] = struct{}{}
+	c.disposables = append(c.disposables, v)
+}
+
+// Destroy calls Destroy of the disposable objects in the reverse order of their creation, an object which is
+// not destroyed within the timeout is abandoned. All the objects are destroyed even if some of them fail.
+func (c *Container) Destroy(timeout time.Duration) error {
+	c.lock.Lock()
+	disposables := c.disposables
+	c.disposables, c.tracked = nil, nil
+	c.lock.Unlock()
+	messages := make([]string, 0)
+	for i := len(disposables) - 1; i >= 0; i-- {
+		if err := destroy(disposables[i], timeout); err != nil {
+			messages = append(messages, fmt.Sprintf("%s: %v", disposables[i].Type(), err))
+			continue
+		}
+		common.DEBUG("Object %s is destroyed", disposables[i].Type())
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("failed to destroy objects: %s", strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// destroy calls Destroy of the object, it waits for it without limit when the timeout is not positive.
+func destroy(v reflect.Value, timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				done <- fmt.Errorf("panic: %v", err)
+			}
+		}()
+		done <- v.Interface().(Disposable).Destroy()
+	}()
+	if timeout <= 0 {
+		return <-done
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("not destroyed within %s", timeout)
+	}
+}
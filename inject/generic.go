@@ -0,0 +1,47 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"reflect"
+)
+
+// TypeOf returns the reflect.Type of T, an interface type included, e.g. TypeOf[redis.Cmdable]().
+func TypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Typed returns the bean registered or provided as T, unwrapped from the reflect.Value kept by the container.
+func Typed[T any](c *Container) (T, bool) {
+	return unbox[T](c.GetTyped(TypeOf[T]()))
+}
+
+// Named returns the bean of the name, it returns false when the bean is not a T.
+func Named[T any](c *Container, name string) (T, bool) {
+	return unbox[T](c.GetNamed(name))
+}
+
+func unbox[T any](v interface{}, ok bool) (T, bool) {
+	var zero T
+	if !ok {
+		return zero, false
+	}
+	value, ok := v.(reflect.Value)
+	if !ok || !value.IsValid() || !value.CanInterface() {
+		return zero, false
+	}
+	t, ok := value.Interface().(T)
+	return t, ok
+}
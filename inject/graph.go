@@ -0,0 +1,173 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Graph is the dependency graph of a container, the nodes are the types of the beans and the edges are the
+// injection points, a struct field or a constructor parameter like #0.
+type Graph struct {
+	Nodes []*GraphNode `json:"nodes"`
+	Edges []*GraphEdge `json:"edges"`
+}
+
+type GraphNode struct {
+	ID    string   `json:"id"`
+	Names []string `json:"names,omitempty"`
+	Scope string   `json:"scope,omitempty"`
+}
+
+type GraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Via      string `json:"via"`
+	Circular bool   `json:"circular,omitempty"`
+}
+
+// DOT renders the graph in the Graphviz DOT language, the circular edges are dashed.
+func (g *Graph) DOT() string {
+	b := &strings.Builder{}
+	b.WriteString("digraph beans {\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		label := n.ID
+		if n.Scope != "" && n.Scope != ScopeSingleton {
+			label += "\n<" + n.Scope + ">"
+		}
+		if len(n.Names) > 0 {
+			label += "\n" + strings.Join(n.Names, ", ")
+		}
+		fmt.Fprintf(b, "  %s [label=%s];\n", strconv.Quote(n.ID), strconv.Quote(label))
+	}
+	for _, e := range g.Edges {
+		style := ""
+		if e.Circular {
+			style = ", style=dashed, color=red"
+		}
+		fmt.Fprintf(b, "  %s -> %s [label=%s%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(e.Via), style)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+type edgeKey struct {
+	from, via, to string
+}
+
+// graph records the dependencies while the beans are registered, provided and injected.
+type graph struct {
+	lock  sync.Mutex
+	nodes map[string]*GraphNode
+	edges map[edgeKey]*GraphEdge
+}
+
+func newGraph() *graph {
+	return &graph{nodes: make(map[string]*GraphNode), edges: make(map[edgeKey]*GraphEdge)}
+}
+
+func (g *graph) node(id string) *GraphNode {
+	n, ok := g.nodes[id]
+	if !ok {
+		n = &GraphNode{ID: id}
+		g.nodes[id] = n
+	}
+	return n
+}
+
+func (g *graph) addNode(id string, name string, scope string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	n := g.node(id)
+	if name != "" {
+		for _, existing := range n.Names {
+			if existing == name {
+				return
+			}
+		}
+		n.Names = append(n.Names, name)
+		sort.Strings(n.Names)
+	}
+	if scope != "" {
+		n.Scope = scope
+	}
+}
+
+func (g *graph) addEdge(from string, via string, to string, circular bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.node(from)
+	g.node(to)
+	key := edgeKey{from: from, via: via, to: to}
+	if e, ok := g.edges[key]; ok {
+		e.Circular = e.Circular || circular
+		return
+	}
+	g.edges[key] = &GraphEdge{From: from, To: to, Via: via, Circular: circular}
+}
+
+func (g *graph) snapshot() *Graph {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	s := &Graph{Nodes: make([]*GraphNode, 0, len(g.nodes)), Edges: make([]*GraphEdge, 0, len(g.edges))}
+	for _, n := range g.nodes {
+		s.Nodes = append(s.Nodes, &GraphNode{ID: n.ID, Names: append([]string{}, n.Names...), Scope: n.Scope})
+	}
+	for _, e := range g.edges {
+		copied := *e
+		s.Edges = append(s.Edges, &copied)
+	}
+	sort.Slice(s.Nodes, func(i, j int) bool {
+		return s.Nodes[i].ID < s.Nodes[j].ID
+	})
+	sort.Slice(s.Edges, func(i, j int) bool {
+		if s.Edges[i].From != s.Edges[j].From {
+			return s.Edges[i].From < s.Edges[j].From
+		}
+		if s.Edges[i].Via != s.Edges[j].Via {
+			return s.Edges[i].Via < s.Edges[j].Via
+		}
+		return s.Edges[i].To < s.Edges[j].To
+	})
+	return s
+}
+
+// nodeID returns the node of the bean, the dynamic type of an interface value.
+func nodeID(v reflect.Value) (string, bool) {
+	v = unwrap(v)
+	if !v.IsValid() || ((v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()) {
+		return "", false
+	}
+	if v.Kind() == reflect.Struct {
+		// a struct value is created and injected through a pointer
+		return reflect.PtrTo(v.Type()).String(), true
+	}
+	return v.Type().String(), true
+}
+
+// Graph returns a snapshot of the dependency graph of the container.
+func (c *Container) Graph() *Graph {
+	return c.graph.snapshot()
+}
+
+func DependencyGraph() *Graph {
+	return defaultContainer.Graph()
+}
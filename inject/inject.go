@@ -17,7 +17,6 @@ package inject
 import (
 	"fmt"
 	"reflect"
-	"strconv"
 	"strings"
 	"sync"
 
@@ -25,10 +24,43 @@ import (
 	"github.com/stella-go/siu/config"
 )
 
-var (
-	named = &sync.Map{}
-	typed = &sync.Map{}
-)
+var defaultContainer = NewContainer()
+
+// Container is a bean registry, every application owns its own container so
+// that several applications can live in one process.
+type Container struct {
+	named *sync.Map
+	typed *sync.Map
+
+	lock           sync.Mutex
+	providers      *sync.Map
+	namedProviders *sync.Map
+	providerList   []*provider
+	pending        []*provider
+
+	graph         *graph
+	allowCircular bool
+
+	disposables []reflect.Value
+	tracked     map[interface{}]struct{}
+
+	processors []BeanPostProcessor
+}
+
+func NewContainer() *Container {
+	return &Container{named: &sync.Map{}, typed: &sync.Map{}, providers: &sync.Map{}, namedProviders: &sync.Map{}, graph: newGraph(), allowCircular: true}
+}
+
+// SetAllowCircularReferences sets whether an object depending on itself through pointer fields, e.g. A.B.A, is
+// injected with the object not initialized yet, true by default. Otherwise the injection fails with the cycle.
+func (c *Container) SetAllowCircularReferences(allow bool) {
+	c.allowCircular = allow
+}
+
+// DefaultContainer returns the container used by the package level functions.
+func DefaultContainer() *Container {
+	return defaultContainer
+}
 
 type Initializable interface {
 	// run after properties set
@@ -54,86 +86,211 @@ func (r *ConfigResolver) Resolve(key string) (interface{}, bool) {
 	return r.C.Get(key)
 }
 
+func (r *ConfigResolver) Bind(prefix string, out interface{}) error {
+	return config.BindFrom(r.C, prefix, out)
+}
+
+// BindResolver binds a configuration subtree to a struct, fields tagged `@siu:"value='${prefix}'"` of struct or
+// pointer to struct types are bound with it.
+type BindResolver interface {
+	Bind(prefix string, out interface{}) error
+}
+
 func RegisterTyped(refType reflect.Type, obj interface{}) error {
-	if _, ok := typed.Load(refType); ok {
+	return defaultContainer.RegisterTyped(refType, obj)
+}
+
+func RegisterNamed(name string, obj interface{}) error {
+	return defaultContainer.RegisterNamed(name, obj)
+}
+
+func GetTyped(refType reflect.Type) (interface{}, bool) {
+	return defaultContainer.GetTyped(refType)
+}
+
+func GetNamed(name string) (interface{}, bool) {
+	return defaultContainer.GetNamed(name)
+}
+
+func Inject(r ValueResolver, obj interface{}) error {
+	return defaultContainer.Inject(r, obj)
+}
+
+func Process(r ValueResolver, obj interface{}) (interface{}, error) {
+	return defaultContainer.Process(r, obj)
+}
+
+func (c *Container) RegisterTyped(refType reflect.Type, obj interface{}) error {
+	if _, ok := c.providers.Load(refType); ok {
+		common.ERROR("Typed object %s is already provided", refType)
+		return fmt.Errorf("typed object %s is already provided", refType)
+	}
+	if _, ok := c.typed.Load(refType); ok {
 		common.ERROR("Typed object %s is already registered", refType)
 		return fmt.Errorf("typed object %s is already registered", refType)
 	}
-	typed.Store(refType, reflect.ValueOf(obj))
+	c.typed.Store(refType, reflect.ValueOf(obj))
+	if id, ok := nodeID(reflect.ValueOf(obj)); ok {
+		c.graph.addNode(id, "", "")
+	}
+	c.track(reflect.ValueOf(obj))
 	common.DEBUG("Typed object %s registered", refType)
 	return nil
 }
 
-func RegisterNamed(name string, obj interface{}) error {
-	if _, ok := named.Load(name); ok {
+func (c *Container) RegisterNamed(name string, obj interface{}) error {
+	if _, ok := c.namedProviders.Load(name); ok {
+		common.ERROR("Named object \"%s\" is already provided", name)
+		return fmt.Errorf("named object \"%s\" is already provided", name)
+	}
+	if _, ok := c.named.Load(name); ok {
 		common.ERROR("Named object \"%s\" is already registered", name)
 		return fmt.Errorf("named object \"%s\" is already registered", name)
 	}
-	named.Store(name, reflect.ValueOf(obj))
+	c.named.Store(name, reflect.ValueOf(obj))
+	if id, ok := nodeID(reflect.ValueOf(obj)); ok {
+		c.graph.addNode(id, name, "")
+	}
+	c.track(reflect.ValueOf(obj))
 	common.DEBUG("Named object \"%s\" registered", name)
 	return nil
 }
 
-func GetTyped(refType reflect.Type) (interface{}, bool) {
-	return typed.Load(refType)
+// GetTyped returns the reflect.Value of the bean, a provided bean is created on the first lookup.
+func (c *Container) GetTyped(refType reflect.Type) (interface{}, bool) {
+	v, ok, err := c.loadTyped(refType, nil)
+	if err != nil {
+		common.ERROR("Get typed object %s with error:", refType, err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	return v, true
 }
 
-func GetNamed(name string) (interface{}, bool) {
-	return named.Load(name)
+// GetNamed returns the reflect.Value of the bean, a provided bean is created on the first lookup.
+func (c *Container) GetNamed(name string) (interface{}, bool) {
+	v, ok, err := c.loadNamed(name, nil)
+	if err != nil {
+		common.ERROR("Get named object \"%s\" with error:", name, err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	return v, true
 }
 
-func Inject(r ValueResolver, obj interface{}) error {
+// Inject injects obj, the object a BeanPostProcessor replaces obj with is dropped, see Process.
+func (c *Container) Inject(r ValueResolver, obj interface{}) error {
+	_, err := c.Process(r, obj)
+	return err
+}
+
+// Process injects obj like Inject and returns the object the post-processors replaced it with, or obj itself.
+func (c *Container) Process(r ValueResolver, obj interface{}) (interface{}, error) {
 	defer func() {
 		if err := recover(); err != nil {
 			common.ERROR("panic:", err)
 			panic(err)
 		}
 	}()
-	return inject(r, obj, make(map[reflect.Type]reflect.Value))
+	return c.inject(r, obj, &visit{objects: make(map[reflect.Type]reflect.Value)})
+}
+
+// visit is the state of an Inject call, the objects created so far and the fields being injected.
+type visit struct {
+	objects map[reflect.Type]reflect.Value
+	frames  []*frame
+}
+
+type frame struct {
+	typ   reflect.Type
+	field string
+}
+
+func (v *visit) push(typ reflect.Type) *frame {
+	f := &frame{typ: typ}
+	v.frames = append(v.frames, f)
+	return f
+}
+
+func (v *visit) pop() {
+	v.frames = v.frames[:len(v.frames)-1]
 }
 
-func inject(r ValueResolver, obj interface{}, visited map[reflect.Type]reflect.Value) error {
+func (v *visit) top() *frame {
+	if len(v.frames) == 0 {
+		return nil
+	}
+	return v.frames[len(v.frames)-1]
+}
+
+// cycle returns the fields from the object of the type being injected back to the type, e.g.
+// [inject.A.B inject.B.A *inject.A], it returns false when the object is not being injected.
+func (v *visit) cycle(typ reflect.Type) ([]string, bool) {
+	for i, f := range v.frames {
+		if f.typ != typ {
+			continue
+		}
+		path := make([]string, 0, len(v.frames)-i+1)
+		for _, f := range v.frames[i:] {
+			path = append(path, f.typ.Elem().String()+"."+f.field)
+		}
+		return append(path, typ.String()), true
+	}
+	return nil, false
+}
+
+func (c *Container) inject(r ValueResolver, obj interface{}, visited *visit) (interface{}, error) {
 	prefType := reflect.TypeOf(obj)
 	prefValue := reflect.ValueOf(obj)
 	if prefType.Kind() != reflect.Ptr {
-		return fmt.Errorf("the object to be injected must be a pointer")
+		return nil, fmt.Errorf("the object to be injected must be a pointer")
 	}
-	if _, ok := visited[prefType]; ok {
-		return nil
+	if _, ok := visited.objects[prefType]; ok {
+		return obj, nil
 	}
-	visited[prefType] = prefValue
+	visited.objects[prefType] = prefValue
 	refType := prefType.Elem()
 	refValue := prefValue.Elem()
 	if refType.Kind() != reflect.Struct {
-		return nil
+		return obj, nil
 	}
 	common.DEBUG("Process object of type %s", prefType)
+	c.graph.addNode(prefType.String(), "", "")
+	current := visited.push(prefType)
+	defer visited.pop()
 	for i := 0; i < refType.NumField(); i++ {
 		fieldType := refType.Field(i)
 		_, ok := fieldType.Tag.Lookup("@siu")
 		if !ok {
 			continue
 		}
+		current.field = fieldType.Name
 		fieldValue := refValue.Field(i)
-		err := setValue(r, fieldType, fieldValue, visited)
+		err := c.setValue(r, fieldType, fieldValue, visited)
 		if err != nil {
 			common.ERROR("Inject field %s.%s with error:", refType, fieldType.Name, err)
-			return err
+			return nil, prependPath(refType.String()+"."+fieldType.Name, err)
 		}
 	}
-	if prefType.Implements(initializableType) {
-		method := prefValue.MethodByName("Init")
-		method.Call(nil)
-		common.DEBUG("Execute the initialization method of %s", prefType)
-	} else if refType.Implements(initializableType) {
-		method := refValue.MethodByName("Init")
-		method.Call(nil)
-		common.DEBUG("Execute the initialization method of %s", refType)
+	bean, err := c.postProcess(obj, false)
+	if err != nil {
+		return nil, &DependencyError{Path: []string{prefType.String()}, Err: err}
+	}
+	if initializable, ok := bean.(Initializable); ok {
+		initializable.Init()
+		common.DEBUG("Execute the initialization method of %T", bean)
 	}
-	return nil
+	if bean, err = c.postProcess(bean, true); err != nil {
+		return nil, &DependencyError{Path: []string{prefType.String()}, Err: err}
+	}
+	return bean, nil
 }
 
-func setValue(r ValueResolver, field reflect.StructField, val reflect.Value, visited map[reflect.Type]reflect.Value) error {
+func (c *Container) setValue(r ValueResolver, field reflect.StructField, val reflect.Value, visited *visit) error {
 	tag, ok := field.Tag.Lookup("@siu")
 	if !ok {
 		return nil
@@ -147,7 +304,14 @@ func setValue(r ValueResolver, field reflect.StructField, val reflect.Value, vis
 	if err != nil {
 		return err
 	}
-	if isValueType(field.Type) {
+	if l, ok := lazyField(val); ok {
+		c.bindLazy(r, field, l, visited)
+		return nil
+	}
+	if prefix, ok := bindPrefix(tagMap, field.Type); ok {
+		return bindValue(r, prefix, field.Type, val)
+	}
+	if _, hasValue := tagMap["value"]; isValueType(field.Type) || (hasValue && isValueCollection(field.Type)) {
 		value, zero, err := resolveValue(tagMap, r, field.Type)
 		if err != nil {
 			return err
@@ -159,7 +323,7 @@ func setValue(r ValueResolver, field reflect.StructField, val reflect.Value, vis
 	} else {
 		switch field.Type.Kind() {
 		case reflect.Interface:
-			value, zero, err := resolveInterface(tagMap, r, field.Type)
+			value, zero, err := c.resolveInterface(tagMap, r, field.Type)
 			if err != nil {
 				return err
 			}
@@ -167,8 +331,9 @@ func setValue(r ValueResolver, field reflect.StructField, val reflect.Value, vis
 				return nil
 			}
 			val.Set(value)
+			c.depend(visited, value)
 		case reflect.Ptr:
-			value, zero, err := resolvePtr(tagMap, r, field.Type, visited)
+			value, zero, err := c.resolvePtr(tagMap, r, field.Type, visited)
 			if err != nil {
 				return err
 			}
@@ -176,14 +341,31 @@ func setValue(r ValueResolver, field reflect.StructField, val reflect.Value, vis
 				return nil
 			}
 			val.Set(value)
+			c.depend(visited, value)
 		case reflect.Struct:
-			value, _, err := resolveStruct(tagMap, r, field.Type, visited)
+			value, _, err := c.resolveStruct(tagMap, r, field.Type, visited)
 			if err != nil {
 				return err
 			}
 			val.Set(value)
+			c.depend(visited, value)
+		case reflect.Slice, reflect.Map:
+			var value reflect.Value
+			var err error
+			if isBeanCollection(field.Type) {
+				value, err = c.resolveCollection(field.Type, visited)
+			} else {
+				value, err = c.create(r, field.Type, visited)
+			}
+			if err != nil {
+				return err
+			}
+			val.Set(value)
+			if isBeanCollection(field.Type) {
+				c.depend(visited, value)
+			}
 		default:
-			value, err := create(r, field.Type, visited)
+			value, err := c.create(r, field.Type, visited)
 			if err != nil {
 				return err
 			}
@@ -193,11 +375,39 @@ func setValue(r ValueResolver, field reflect.StructField, val reflect.Value, vis
 	return nil
 }
 
-func resolveInterface(tagMap map[string]string, _ /*r*/ ValueResolver, typ reflect.Type) (reflect.Value, bool, error) {
+// depend records the edges from the object being injected to the beans injected into its current field.
+func (c *Container) depend(visited *visit, value reflect.Value) {
+	current := visited.top()
+	if current == nil {
+		return
+	}
+	switch value.Kind() {
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			c.depend(visited, value.Index(i))
+		}
+		return
+	case reflect.Map:
+		for iter := value.MapRange(); iter.Next(); {
+			c.depend(visited, iter.Value())
+		}
+		return
+	}
+	if id, ok := nodeID(value); ok {
+		c.graph.addEdge(current.typ.String(), current.field, id, false)
+	}
+}
+
+func (c *Container) resolveInterface(tagMap map[string]string, _ /*r*/ ValueResolver, typ reflect.Type) (reflect.Value, bool, error) {
+	if v, zero, found, err := c.resolveQualifier(tagMap, typ); found || err != nil {
+		return v, zero, err
+	}
 	if name, ok := tagMap["name"]; ok {
-		if v, ok := named.Load(name); ok {
+		if v, ok, err := c.loadNamed(name, nil); err != nil {
+			return reflect.Value{}, true, err
+		} else if ok {
 			common.DEBUG("Found interface %s with name \"%s\"", typ, name)
-			return v.(reflect.Value), false, nil
+			return v, false, nil
 		} else {
 			if defaultValue, ok := tagMap["default"]; ok && defaultValue == "zero" {
 				common.DEBUG("Not found interface %s with name \"%s\", default is zero", typ, name)
@@ -205,9 +415,11 @@ func resolveInterface(tagMap map[string]string, _ /*r*/ ValueResolver, typ refle
 			}
 		}
 	}
-	if v, ok := typed.Load(typ); ok {
+	if v, ok, err := c.loadTyped(typ, nil); err != nil {
+		return reflect.Value{}, true, err
+	} else if ok {
 		common.DEBUG("Found interface %s with type \"%s\"", typ, typ)
-		return v.(reflect.Value), false, nil
+		return v, false, nil
 	} else {
 		if defaultValue, ok := tagMap["default"]; ok && defaultValue == "zero" {
 			common.DEBUG("Not found interface %s with type %s, default is zero", typ, typ)
@@ -215,22 +427,27 @@ func resolveInterface(tagMap map[string]string, _ /*r*/ ValueResolver, typ refle
 		}
 	}
 	common.ERROR("Not found interface %s", typ)
-	return reflect.Value{}, true, fmt.Errorf("interface type %s not found", typ)
+	return reflect.Value{}, true, &DependencyError{Path: []string{typ.String()}, Err: fmt.Errorf("typed object not found")}
 }
 
-func resolvePtr(tagMap map[string]string, r ValueResolver, typ reflect.Type, visited map[reflect.Type]reflect.Value) (reflect.Value, bool, error) {
+func (c *Container) resolvePtr(tagMap map[string]string, r ValueResolver, typ reflect.Type, visited *visit) (reflect.Value, bool, error) {
 	if t, ok := tagMap["type"]; ok && t == "private" {
-		value, err := create(r, typ, visited)
+		value, err := c.create(r, typ, visited)
 		if err != nil {
-			return reflect.Value{}, true, nil
+			return reflect.Value{}, true, err
 		}
 		common.DEBUG("Create private object %s", typ)
 		return value, false, nil
 	}
+	if v, zero, found, err := c.resolveQualifier(tagMap, typ); found || err != nil {
+		return v, zero, err
+	}
 	if name, ok := tagMap["name"]; ok {
-		if v, ok := named.Load(name); ok {
+		if v, ok, err := c.loadNamed(name, nil); err != nil {
+			return reflect.Value{}, true, err
+		} else if ok {
 			common.DEBUG("Found object %s with name \"%s\"", typ, name)
-			return v.(reflect.Value), false, nil
+			return v, false, nil
 		} else {
 			if defaultValue, ok := tagMap["default"]; ok && defaultValue == "zero" {
 				common.DEBUG("Not found object %s with name \"%s\", default is zero", typ, name)
@@ -238,53 +455,95 @@ func resolvePtr(tagMap map[string]string, r ValueResolver, typ reflect.Type, vis
 			}
 		}
 	}
-	if v, ok := typed.Load(typ); ok {
+	if v, ok, err := c.loadTyped(typ, nil); err != nil {
+		return reflect.Value{}, true, err
+	} else if ok {
 		common.DEBUG("Found object %s with type %s", typ, typ)
-		return v.(reflect.Value), false, nil
+		return v, false, nil
 	} else {
 		if defaultValue, ok := tagMap["default"]; ok && defaultValue == "zero" {
 			common.DEBUG("Not found object %s with type %s, default is zero", typ, typ)
 			return reflect.Value{}, true, nil
 		}
 	}
-	value, err := create(r, typ, visited)
+	value, err := c.create(r, typ, visited)
 	if err != nil {
 		return reflect.Value{}, true, err
 	}
 	if name, ok := tagMap["name"]; ok {
-		named.Store(name, value)
+		c.named.Store(name, value)
 	}
-	typed.Store(typ, value)
+	c.typed.Store(typ, value)
+	c.track(value)
 	return value, false, nil
 }
 
-func resolveStruct(_ /*tagMap*/ map[string]string, r ValueResolver, typ reflect.Type, visited map[reflect.Type]reflect.Value) (reflect.Value, bool, error) {
-	value, err := create(r, typ, visited)
+// resolveQualifier looks up the object of the qualifier tag, found is false when there is no qualifier tag, or
+// the object is not found and the default tag is 'type'.
+func (c *Container) resolveQualifier(tagMap map[string]string, typ reflect.Type) (value reflect.Value, zero bool, found bool, err error) {
+	qualifier, ok := tagMap["qualifier"]
+	if !ok {
+		return reflect.Value{}, true, false, nil
+	}
+	if v, ok, err := c.loadQualified(qualifier, typ, nil); err != nil {
+		return reflect.Value{}, true, true, err
+	} else if ok {
+		common.DEBUG("Found object %s with qualifier \"%s\"", typ, qualifier)
+		return v, false, true, nil
+	}
+	switch tagMap["default"] {
+	case "zero":
+		common.DEBUG("Not found object %s with qualifier \"%s\", default is zero", typ, qualifier)
+		return reflect.Value{}, true, true, nil
+	case "type":
+		return reflect.Value{}, true, false, nil
+	}
+	common.ERROR("Not found object %s with qualifier \"%s\"", typ, qualifier)
+	return reflect.Value{}, true, true, &DependencyError{Path: []string{fmt.Sprintf("%s(\"%s\")", typ, qualifier)}, Err: fmt.Errorf("qualified object not found")}
+}
+
+func (c *Container) resolveStruct(_ /*tagMap*/ map[string]string, r ValueResolver, typ reflect.Type, visited *visit) (reflect.Value, bool, error) {
+	value, err := c.create(r, typ, visited)
 	if err != nil {
-		return reflect.Value{}, true, nil
+		return reflect.Value{}, true, err
 	}
 	return value, false, nil
 }
 
-func create(r ValueResolver, typ reflect.Type, visited map[reflect.Type]reflect.Value) (reflect.Value, error) {
-	if value, ok := visited[typ]; ok {
+func (c *Container) create(r ValueResolver, typ reflect.Type, visited *visit) (reflect.Value, error) {
+	if value, ok := visited.objects[typ]; ok {
+		if path, ok := visited.cycle(typ); ok {
+			current := visited.top()
+			c.graph.addEdge(current.typ.String(), current.field, typ.String(), true)
+			if !c.allowCircular {
+				return reflect.Value{}, &DependencyError{Path: []string{typ.String()}, Err: fmt.Errorf("circular dependency")}
+			}
+			common.WARN("Detected circular dependency %s, the object is injected before its initialization", strings.Join(path, " -> "))
+			return value, nil
+		}
 		common.DEBUG("Detected recursive dependency, skipping creation of object %s", typ)
 		return value, nil
 	}
 	var value reflect.Value
 	switch typ.Kind() {
 	case reflect.Struct:
-		pvalue := reflect.New(typ)
-		err := inject(r, pvalue.Interface(), visited)
+		bean, err := c.inject(r, reflect.New(typ).Interface(), visited)
 		if err != nil {
 			return value, err
 		}
+		pvalue, err := assignable(bean, reflect.PtrTo(typ))
+		if err != nil {
+			return reflect.Value{}, err
+		}
 		value = pvalue.Elem()
 		common.DEBUG("Create object %s", typ)
 		return value, nil
 	case reflect.Ptr:
-		value = reflect.New(typ.Elem())
-		err := inject(r, value.Interface(), visited)
+		bean, err := c.inject(r, reflect.New(typ.Elem()).Interface(), visited)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value, err = assignable(bean, typ)
 		if err != nil {
 			return reflect.Value{}, err
 		}
@@ -296,7 +555,7 @@ func create(r ValueResolver, typ reflect.Type, visited map[reflect.Type]reflect.
 		return v.Elem(), nil
 	default:
 		value := reflect.Zero(typ)
-		err := inject(r, value.Interface(), visited)
+		_, err := c.inject(r, value.Interface(), visited)
 		if err != nil {
 			return reflect.Value{}, err
 		}
@@ -305,6 +564,80 @@ func create(r ValueResolver, typ reflect.Type, visited map[reflect.Type]reflect.
 	}
 }
 
+// bindPrefix returns the configuration prefix when the struct field is bound from a configuration subtree.
+func bindPrefix(tagMap map[string]string, typ reflect.Type) (string, bool) {
+	valueTag, ok := tagMap["value"]
+	if !ok || !strings.HasPrefix(valueTag, "${") || !strings.HasSuffix(valueTag, "}") {
+		return "", false
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return "", false
+	}
+	return valueTag[2 : len(valueTag)-1], true
+}
+
+func bindValue(r ValueResolver, prefix string, typ reflect.Type, val reflect.Value) error {
+	binder, ok := r.(BindResolver)
+	if !ok {
+		return fmt.Errorf("type %s value with key \"%s\" can not be bound, resolver %T is not a BindResolver", typ, prefix, r)
+	}
+	if typ.Kind() == reflect.Ptr {
+		value := reflect.New(typ.Elem())
+		if err := binder.Bind(prefix, value.Interface()); err != nil {
+			return err
+		}
+		val.Set(value)
+	} else {
+		if err := binder.Bind(prefix, val.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	common.DEBUG("Bind type %s value with key \"%s\"", typ, prefix)
+	return nil
+}
+
+// Validate binds the configuration of the fields tagged `@siu:"value='${prefix}'"` of obj to throwaway values,
+// so that the invalid configuration is reported before obj is injected.
+func Validate(r ValueResolver, obj interface{}) error {
+	if _, ok := r.(BindResolver); !ok {
+		return nil
+	}
+	typ := reflect.TypeOf(obj)
+	if typ == nil {
+		return nil
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	errs := &config.ValidationError{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag, ok := field.Tag.Lookup("@siu")
+		if !ok {
+			continue
+		}
+		tagMap, err := extractTag(tag)
+		if err != nil {
+			continue
+		}
+		if prefix, ok := bindPrefix(tagMap, field.Type); ok {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			ptrType := reflect.PtrTo(fieldType)
+			errs.Append(bindValue(r, prefix, ptrType, reflect.New(ptrType).Elem()))
+		}
+	}
+	return errs.Err()
+}
+
 func isValueType(t reflect.Type) bool {
 	switch t.Kind() {
 	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
@@ -314,6 +647,19 @@ func isValueType(t reflect.Type) bool {
 	}
 }
 
+// isValueCollection reports whether t is a slice or a map of value types, e.g. []string or map[string]int,
+// which is resolved from the configuration when the field has a value tag.
+func isValueCollection(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+		return isValueType(t.Elem())
+	case reflect.Map:
+		return isValueType(t.Key()) && (isValueType(t.Elem()) || t.Elem().Kind() == reflect.Interface && t.Elem().NumMethod() == 0)
+	default:
+		return false
+	}
+}
+
 func resolveValue(tagMap map[string]string, r ValueResolver, typ reflect.Type) (reflect.Value, bool, error) {
 	if valueTag, ok := tagMap["value"]; ok {
 		if strings.HasPrefix(valueTag, "${") && strings.HasSuffix(valueTag, "}") {
@@ -324,7 +670,10 @@ func resolveValue(tagMap map[string]string, r ValueResolver, typ reflect.Type) (
 			if index := strings.Index(placeholder, ":"); index != -1 {
 				key, defaultValue := placeholder[0:index], placeholder[index+1:]
 				if value, ok := r.Resolve(key); ok {
-					convertValue := reflect.ValueOf(value).Convert(typ)
+					convertValue, err := config.Convert(value, typ)
+					if err != nil {
+						return reflect.Value{}, true, fmt.Errorf("type %s value with key \"%s\": %v", typ, valueTag, err)
+					}
 					common.DEBUG("Found type %s value with key \"%s\": \"%v\"", typ, valueTag, value)
 					return convertValue, convertValue.IsZero(), nil
 				} else {
@@ -337,7 +686,10 @@ func resolveValue(tagMap map[string]string, r ValueResolver, typ reflect.Type) (
 				}
 			} else {
 				if value, ok := r.Resolve(placeholder); ok {
-					convertValue := reflect.ValueOf(value).Convert(typ)
+					convertValue, err := config.Convert(value, typ)
+					if err != nil {
+						return reflect.Value{}, true, fmt.Errorf("type %s value with key \"%s\": %v", typ, valueTag, err)
+					}
 					common.DEBUG("Found type %s value with key \"%s\": \"%v\"", typ, valueTag, value)
 					return convertValue, convertValue.IsZero(), nil
 				} else {
@@ -370,84 +722,14 @@ func resolveValueWithDefault(tagMap map[string]string, _ /*r*/ ValueResolver, ty
 }
 
 func convertString(value string, typ reflect.Type) (reflect.Value, error) {
-	switch typ.Kind() {
-	case reflect.Bool:
-		boolValue, err := strconv.ParseBool(value)
-		if err != nil {
-			return reflect.Value{}, err
-		}
-		return reflect.ValueOf(boolValue), nil
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		intValue, err := strconv.ParseInt(value, 10, 64)
-		if err != nil {
-			return reflect.Value{}, err
-		}
-		return reflect.ValueOf(intValue).Convert(typ), nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		uintValue, err := strconv.ParseInt(value, 10, 64)
-		if err != nil {
-			return reflect.Value{}, err
-		}
-		return reflect.ValueOf(uintValue).Convert(typ), nil
-	case reflect.Float32, reflect.Float64:
-		floatValue, err := strconv.ParseFloat(value, 64)
-		if err != nil {
-			return reflect.Value{}, err
-		}
-		return reflect.ValueOf(floatValue).Convert(typ), nil
-	case reflect.Complex64, reflect.Complex128:
-		complexValue, err := strconv.ParseComplex(value, 128)
-		if err != nil {
-			return reflect.Value{}, err
-		}
-		return reflect.ValueOf(complexValue).Convert(typ), nil
-	}
-	return reflect.ValueOf(value).Convert(typ), nil
+	return config.Convert(value, typ)
 }
 
 func extractTag(tag string) (map[string]string, error) {
-	// tag example `@siu:"name='abc',value='${a.b.c}',default='type',type='private'"`
-	r := make(map[string]string)
-
-	syntaxErr := fmt.Errorf("@siu inject tag syntax error: %s", tag)
-	r["tag"] = tag
-	for tag != "" {
-		// skip leading space and comma
-		i := 0
-		for i < len(tag) && (tag[i] == ' ' || tag[i] == ',') {
-			i++
-		}
-		tag = tag[i:]
-		if tag == "" {
-			break
-		}
-		// scan to equals mark.
-		// a space or a quote is a syntax error
-		i = 0
-		for i < len(tag) && tag[i] != ' ' && tag[i] != '\'' && tag[i] != '=' {
-			i++
-		}
-		if i+1 >= len(tag) || tag[i] != '=' || tag[i+1] != '\'' {
-			return nil, syntaxErr
-		}
-		name := string(tag[:i])
-		tag = tag[i+1:]
-
-		// scan quoted string to find value
-		i = 1
-		for i < len(tag) && tag[i] != '\'' {
-			if tag[i] == '\\' {
-				i++
-			}
-			i++
-		}
-		if i >= len(tag) {
-			return nil, syntaxErr
-		}
-		value := strings.ReplaceAll(tag[1:i], "\\", "")
-		r[name] = value
-
-		tag = tag[i+1:]
+	// tag example `@siu:"name='abc',qualifier='abc',value='${a.b.c}',default='type',type='private'"`
+	tagMap, err := config.ParseTag(tag)
+	if err != nil {
+		return nil, fmt.Errorf("@siu inject %v", err)
 	}
-	return r, nil
+	return tagMap, nil
 }
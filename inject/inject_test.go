@@ -15,11 +15,18 @@
 package inject
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stella-go/logger"
 	"github.com/stella-go/siu/common"
+	"github.com/stella-go/siu/config"
 )
 
 type SS struct {
@@ -209,3 +216,691 @@ func TestVisited(t *testing.T) {
 	fmt.Println(a.B.A)
 	fmt.Println(a.B.A.B)
 }
+
+func TestContainer(t *testing.T) {
+	c1 := NewContainer()
+	c2 := NewContainer()
+	ss := &SS{SSint: 1}
+	if err := c1.RegisterNamed("abc", ss); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c2.GetNamed("abc"); ok {
+		t.Fatal("bean leaked into another container")
+	}
+	type St struct {
+		SS *SS `@siu:"name='abc'"`
+	}
+	st := &St{}
+	if err := c1.Inject(&NopValueResolver{}, st); err != nil {
+		t.Fatal(err)
+	}
+	if st.SS != ss {
+		t.FailNow()
+	}
+	if err := c2.Inject(&NopValueResolver{}, &St{}); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := c2.GetTyped(reflect.TypeOf((*SS)(nil))); !ok || v.(reflect.Value).Interface() == ss {
+		t.FailNow()
+	}
+}
+
+type MapConfig map[string]interface{}
+
+func (m MapConfig) Get(key string) (interface{}, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+func (m MapConfig) GetOr(key string, defaultValue interface{}) interface{} {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	return defaultValue
+}
+
+func TestBind(t *testing.T) {
+	type Redis struct {
+		Addr    string
+		Timeout time.Duration `config:"default='5s'"`
+	}
+	type St struct {
+		Redis  Redis  `@siu:"value='${redis}'"`
+		PRedis *Redis `@siu:"value='${redis}'"`
+	}
+	st := &St{}
+	r := &ConfigResolver{C: MapConfig{"redis": map[interface{}]interface{}{}, "redis.addr": "127.0.0.1:6379"}}
+	if err := Inject(r, st); err != nil {
+		t.Fatal(err)
+	}
+	if st.Redis.Addr != "127.0.0.1:6379" || st.Redis.Timeout != 5*time.Second || st.PRedis == nil || *st.PRedis != st.Redis {
+		t.Fatal(st)
+	}
+	if err := Inject(&Resolver{}, &St{}); err == nil {
+		t.FailNow()
+	}
+}
+
+func TestValidate(t *testing.T) {
+	type Redis struct {
+		Addr string `config:"required='true'"`
+		DB   int    `config:"max='15'"`
+	}
+	type St struct {
+		Redis  Redis  `@siu:"value='${redis}'"`
+		Cache  *Redis `@siu:"value='${cache}'"`
+		Plain  string `@siu:"value='${plain:}'"`
+		Ignore Redis
+	}
+	r := &ConfigResolver{C: MapConfig{"cache": map[interface{}]interface{}{}, "cache.addr": "127.0.0.1:6379", "cache.db": 16}}
+	err := Validate(r, &St{})
+	ve, ok := err.(*config.ValidationError)
+	if !ok || len(ve.Errors) != 2 || ve.Errors[0].Key != "cache.db" || ve.Errors[1].Key != "redis.addr" {
+		t.Fatal(err)
+	}
+	if err := Validate(&Resolver{}, &St{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValueConversion(t *testing.T) {
+	r := &ConfigResolver{C: MapConfig{"port": "8080", "hosts": []interface{}{"a", "b"}, "timeout": "5s", "labels": map[interface{}]interface{}{"app": "siu"}}}
+	type St struct {
+		Port    int               `@siu:"value='${port}'"`
+		Hosts   []string          `@siu:"value='${hosts}'"`
+		Default []int             `@siu:"value='${none:1,2}'"`
+		Timeout time.Duration     `@siu:"value='${timeout}'"`
+		Labels  map[string]string `@siu:"value='${labels}'"`
+	}
+	st := &St{}
+	if err := Inject(r, st); err != nil {
+		t.Fatal(err)
+	}
+	if st.Port != 8080 || len(st.Hosts) != 2 || st.Hosts[1] != "b" || len(st.Default) != 2 || st.Default[1] != 2 || st.Timeout != 5*time.Second || st.Labels["app"] != "siu" {
+		t.Fatal(st)
+	}
+	type Bad struct {
+		Port int `@siu:"value='${hosts}'"`
+	}
+	if err := Inject(r, &Bad{}); err == nil {
+		t.FailNow()
+	}
+}
+
+type Repo struct {
+	DSN string
+}
+
+type Service struct {
+	Repo *Repo
+}
+
+type Handler struct {
+	Service *Service `@siu:""`
+}
+
+func TestProvide(t *testing.T) {
+	c := NewContainer()
+	calls := 0
+	if err := c.Provide(func(repo *Repo) (*Service, error) {
+		calls++
+		return &Service{Repo: repo}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RegisterNamed("primary", &Repo{DSN: "primary"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Provide(func(repo *Repo) *Repo { return repo }, WithParamNames("primary")); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Fatal("constructor is not lazy")
+	}
+	h := &Handler{}
+	if err := c.Inject(&NopValueResolver{}, h); err != nil {
+		t.Fatal(err)
+	}
+	if h.Service == nil || h.Service.Repo.DSN != "primary" {
+		t.Fatal(h.Service)
+	}
+	if err := c.ResolveProviders(); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := c.GetTyped(reflect.TypeOf((*Service)(nil))); !ok || v.(reflect.Value).Interface() != h.Service || calls != 1 {
+		t.Fatal(v, calls)
+	}
+	if err := c.Provide(func() *Service { return nil }); err == nil {
+		t.Fatal("duplicated provider")
+	}
+	if err := c.Provide(func() (*Service, string) { return nil, "" }); err == nil {
+		t.Fatal("invalid constructor")
+	}
+}
+
+func TestProvideError(t *testing.T) {
+	c := NewContainer()
+	c.Provide(func(repo *Repo) *Service { return &Service{Repo: repo} })
+	c.Provide(func() (*Repo, error) { return nil, fmt.Errorf("connection refused") })
+	err := c.Inject(&NopValueResolver{}, &Handler{})
+	var de *DependencyError
+	if !errors.As(err, &de) || err.Error() != "failed to create inject.Handler.Service -> *inject.Service -> *inject.Repo: connection refused" {
+		t.Fatal(err)
+	}
+
+	type X struct{}
+	type Y struct{}
+	c = NewContainer()
+	c.Provide(func(*Y) *X { return &X{} })
+	c.Provide(func(*X) *Y { return &Y{} })
+	if err := c.ResolveProviders(); err == nil || err.Error() != "failed to create *inject.X -> *inject.Y -> *inject.X: circular dependency" {
+		t.Fatal(err)
+	}
+	c = NewContainer()
+	c.Provide(func(*Y) *X { return &X{} })
+	if err := c.ResolveProviders(); err == nil || err.Error() != "failed to create *inject.X -> *inject.Y: typed object not found" {
+		t.Fatal(err)
+	}
+}
+
+type Tx struct {
+	ID     int
+	closed *[]int
+}
+
+func (t *Tx) Close() error {
+	*t.closed = append(*t.closed, t.ID)
+	return nil
+}
+
+type Session struct {
+	Tx *Tx
+}
+
+func TestScopes(t *testing.T) {
+	c := NewContainer()
+	ids := 0
+	closed := []int{}
+	c.Provide(func() *Repo {
+		ids++
+		return &Repo{DSN: fmt.Sprint(ids)}
+	}, WithScope(ScopePrototype))
+	c.Provide(func() *Tx {
+		ids++
+		return &Tx{ID: ids, closed: &closed}
+	}, WithScope(ScopeRequest))
+	c.Provide(func(tx *Tx) *Session { return &Session{Tx: tx} }, WithScope(ScopeRequest), WithName("session"))
+	if err := c.ResolveProviders(); err != nil {
+		t.Fatal(err)
+	}
+
+	type Two struct {
+		A *Repo `@siu:""`
+		B *Repo `@siu:""`
+	}
+	two := &Two{}
+	if err := c.Inject(&NopValueResolver{}, two); err != nil {
+		t.Fatal(err)
+	}
+	if two.A == two.B {
+		t.Fatal("prototype is shared")
+	}
+
+	type Singleton struct {
+		Tx *Tx `@siu:""`
+	}
+	if err := c.Inject(&NopValueResolver{}, &Singleton{}); err == nil {
+		t.Fatal("request scoped object injected outside a request")
+	}
+
+	s1, s2 := c.NewRequestScope(), c.NewRequestScope()
+	tx1, _ := s1.GetTyped(reflect.TypeOf((*Tx)(nil)))
+	session1, _ := s1.GetNamed("session")
+	tx2, _ := s2.GetTyped(reflect.TypeOf((*Tx)(nil)))
+	if session1.(reflect.Value).Interface().(*Session).Tx != tx1.(reflect.Value).Interface() {
+		t.Fatal("request scoped object is not shared in a request")
+	}
+	if tx1.(reflect.Value).Interface() == tx2.(reflect.Value).Interface() {
+		t.Fatal("request scoped object is shared between requests")
+	}
+	if err := s1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(closed) != 1 || closed[0] != tx1.(reflect.Value).Interface().(*Tx).ID {
+		t.Fatal(closed)
+	}
+	if err := c.Provide(func() *Service { return nil }, WithScope("session")); err == nil {
+		t.Fatal("unknown scope")
+	}
+}
+
+func TestSingletons(t *testing.T) {
+	c := NewContainer()
+	repo := &Repo{}
+	c.Provide(func() *Repo { return repo })
+	c.Provide(func() *Tx { return &Tx{} }, WithScope(ScopeRequest))
+	c.Provide(func() *Service { return &Service{} }, WithLazy())
+	c.Provide(func() *Session { return nil }, WithLazy())
+	if err := c.ResolveProviders(); err != nil {
+		t.Fatal(err)
+	}
+	if singletons := c.Singletons(); len(singletons) != 1 || singletons[0] != repo {
+		t.Fatal(singletons)
+	}
+	service, _ := Typed[*Service](c)
+	if singletons := c.Singletons(); len(singletons) != 2 || singletons[1] != service {
+		t.Fatal(singletons)
+	}
+}
+
+func TestRequestScopeConcurrency(t *testing.T) {
+	c := NewContainer()
+	var calls int32
+	c.Provide(func() *Service {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return &Service{}
+	}, WithScope(ScopeRequest))
+	scope := c.NewRequestScope()
+	services := make([]interface{}, 8)
+	wg := &sync.WaitGroup{}
+	for i := range services {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, _ := scope.GetTyped(TypeOf[*Service]())
+			services[i] = v.(reflect.Value).Interface()
+		}(i)
+	}
+	wg.Wait()
+	for _, service := range services {
+		if service != services[0] {
+			t.Fatal("request scoped object is created twice in a request")
+		}
+	}
+	if calls != 1 {
+		t.Fatal(calls)
+	}
+}
+
+type EventHandler interface {
+	Handle() string
+}
+
+type HandlerA struct{}
+
+func (*HandlerA) Handle() string { return "a" }
+func (*HandlerA) Order() int     { return 2 }
+
+type HandlerB struct{}
+
+func (*HandlerB) Handle() string { return "b" }
+func (*HandlerB) Order() int     { return 1 }
+
+type HandlerC struct{}
+
+func (*HandlerC) Handle() string { return "c" }
+
+type Dispatcher struct {
+	Handlers []EventHandler          `@siu:""`
+	ByName   map[string]EventHandler `@siu:""`
+	Repos    []*Repo                 `@siu:""`
+	Ints     []int                   `@siu:""`
+}
+
+func (*Dispatcher) Handle() string { return "dispatcher" }
+
+func TestCollection(t *testing.T) {
+	c := NewContainer()
+	a := &HandlerA{}
+	c.RegisterNamed("a", a)
+	c.RegisterTyped(reflect.TypeOf(a), a)
+	c.RegisterTyped(reflect.TypeOf((*HandlerB)(nil)), &HandlerB{})
+	c.Provide(func() *HandlerC { return &HandlerC{} }, WithName("c"))
+	c.RegisterNamed("repo", &Repo{})
+
+	d := &Dispatcher{}
+	c.RegisterNamed("dispatcher", d)
+	if err := c.Inject(&NopValueResolver{}, d); err != nil {
+		t.Fatal(err)
+	}
+	handled := ""
+	for _, h := range d.Handlers {
+		handled += h.Handle()
+	}
+	if handled != "bac" {
+		t.Fatal(handled)
+	}
+	if len(d.ByName) != 3 || d.ByName["a"] != a || d.ByName["c"].Handle() != "c" || d.ByName["*inject.HandlerB"].Handle() != "b" {
+		t.Fatal(d.ByName)
+	}
+	if len(d.Repos) != 1 || len(d.Ints) != 0 {
+		t.Fatal(d.Repos, d.Ints)
+	}
+}
+
+type Store struct {
+	name    string
+	primary bool
+}
+
+func (s *Store) Primary() bool {
+	return s.primary
+}
+
+type Report struct {
+	Main      *Store `@siu:""`
+	Reporting *Store `@siu:"qualifier='reporting'"`
+	Archive   *Store `@siu:"qualifier='archive',default='zero'"`
+}
+
+func TestPrimaryAndQualifier(t *testing.T) {
+	c := NewContainer()
+	c.RegisterNamed("store.main", &Store{name: "main", primary: true})
+	c.RegisterNamed("store.reporting", &Store{name: "reporting"})
+
+	r := &Report{}
+	if err := c.Inject(&NopValueResolver{}, r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Main.name != "main" || r.Reporting.name != "reporting" || r.Archive != nil {
+		t.Fatal(r)
+	}
+
+	c.RegisterNamed("store.backup", &Store{name: "backup", primary: true})
+	if err := c.Inject(&NopValueResolver{}, &Report{}); err == nil || !strings.Contains(err.Error(), "more than one primary object") {
+		t.Fatal(err)
+	}
+
+	c = NewContainer()
+	c.RegisterTyped(reflect.TypeOf((*Store)(nil)), &Store{name: "main"})
+	c.RegisterNamed("a.reporting", &Store{name: "a"})
+	c.RegisterNamed("b.reporting", &Store{name: "b"})
+	if err := c.Inject(&NopValueResolver{}, &Report{}); err == nil || !strings.Contains(err.Error(), "a.reporting, b.reporting") {
+		t.Fatal(err)
+	}
+}
+
+type GraphRouter struct {
+	Service *GraphService `@siu:""`
+}
+
+type GraphService struct {
+	Handler EventHandler `@siu:""`
+}
+
+func TestGraph(t *testing.T) {
+	c := NewContainer()
+	err := c.Inject(&NopValueResolver{}, &GraphRouter{})
+	if err == nil || err.Error() != "failed to create inject.GraphRouter.Service -> inject.GraphService.Handler -> inject.EventHandler: typed object not found" {
+		t.Fatal(err)
+	}
+
+	c = NewContainer()
+	c.RegisterNamed("a", &HandlerA{})
+	c.RegisterTyped(reflect.TypeOf((*EventHandler)(nil)).Elem(), &HandlerA{})
+	if err := c.Inject(&NopValueResolver{}, &GraphRouter{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Inject(&NopValueResolver{}, &A{}); err != nil {
+		t.Fatal(err)
+	}
+	dot := c.Graph().DOT()
+	for _, line := range []string{
+		`"*inject.GraphRouter" -> "*inject.GraphService" [label="Service"];`,
+		`"*inject.GraphService" -> "*inject.HandlerA" [label="Handler"];`,
+		`"*inject.HandlerA" [label="*inject.HandlerA\na"];`,
+		`"*inject.A" -> "*inject.B" [label="B"];`,
+		`"*inject.B" -> "*inject.A" [label="A", style=dashed, color=red];`,
+	} {
+		if !strings.Contains(dot, line) {
+			t.Fatal(dot)
+		}
+	}
+
+	c = NewContainer()
+	c.SetAllowCircularReferences(false)
+	err = c.Inject(&NopValueResolver{}, &A{})
+	if err == nil || err.Error() != "failed to create inject.A.B -> inject.B.A -> *inject.A: circular dependency" {
+		t.Fatal(err)
+	}
+}
+
+type Pool struct {
+	name      string
+	destroyed *[]string
+	err       error
+	delay     time.Duration
+}
+
+func (p *Pool) Destroy() error {
+	if p.delay > 0 {
+		time.Sleep(p.delay)
+		return nil
+	}
+	*p.destroyed = append(*p.destroyed, p.name)
+	return p.err
+}
+
+type PoolUser struct {
+	Pool *Pool `@siu:"name='first'"`
+}
+
+func (u *PoolUser) Destroy() error {
+	*u.Pool.destroyed = append(*u.Pool.destroyed, "user")
+	return nil
+}
+
+func TestDestroy(t *testing.T) {
+	c := NewContainer()
+	destroyed := []string{}
+	first := &Pool{name: "first", destroyed: &destroyed}
+	c.RegisterNamed("first", first)
+	c.RegisterTyped(reflect.TypeOf(first), first)
+	c.RegisterNamed("failing", &Pool{name: "failing", destroyed: &destroyed, err: fmt.Errorf("broken pipe")})
+	c.RegisterNamed("slow", &Pool{name: "slow", destroyed: &destroyed, delay: time.Second})
+	user := &PoolUser{}
+	if err := c.Inject(&NopValueResolver{}, user); err != nil {
+		t.Fatal(err)
+	}
+	c.RegisterNamed("user", user)
+	// an object injected but not registered is not destroyed by the container
+	if err := c.Inject(&NopValueResolver{}, &PoolUser{}); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Destroy(100 * time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "*inject.Pool: not destroyed within 100ms") || !strings.Contains(err.Error(), "*inject.Pool: broken pipe") {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(destroyed) != "[user failing first]" {
+		t.Fatal(destroyed)
+	}
+	if err := c.Destroy(time.Second); err != nil {
+		t.Fatal("destroyed twice", err)
+	}
+}
+
+func TestConditions(t *testing.T) {
+	c := NewContainer()
+	r := &ConfigResolver{C: MapConfig{"store.kind": "memory", "feature.enabled": false}}
+	c.RegisterTyped(reflect.TypeOf((*HandlerA)(nil)), &HandlerA{})
+	c.Provide(func() *Repo { return &Repo{DSN: "memory"} }, WithConditions(OnProperty("store.kind", "memory", "file")))
+	c.Provide(func() *Service { return &Service{} }, WithConditions(OnProperty("feature.enabled")))
+	c.Provide(func() EventHandler { return &HandlerB{} }, WithConditions(OnMissingBean(reflect.TypeOf((*EventHandler)(nil)).Elem())))
+	c.Provide(func() *Tx { return &Tx{} }, WithConditions(OnBean(reflect.TypeOf((*Repo)(nil))), OnProfile("!prod")))
+	c.Provide(func() *Session { return &Session{} }, WithConditions(OnProfile("prod")))
+	c.Provide(func() *Store { return &Store{} }, WithConditions(OnMissingBeanNamed("store")), WithName("store"))
+	c.Provide(func() *Pool { return &Pool{} }, WithConditions(OnBeanNamed("store")))
+	c.Provide(func() *Dispatcher { return &Dispatcher{} }, WithConditions(OnBean(reflect.TypeOf((*HandlerB)(nil)))))
+	if err := c.ActivateProviders(r); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.GetTyped(reflect.TypeOf((*Dispatcher)(nil))); ok {
+		t.Fatal("activated before its bean is registered")
+	}
+	// the bean registered by an auto factory after the first activation
+	c.RegisterTyped(reflect.TypeOf((*HandlerB)(nil)), &HandlerB{})
+	if err := c.ActivateProviders(r); err != nil {
+		t.Fatal(err)
+	}
+	for typ, expected := range map[reflect.Type]bool{
+		reflect.TypeOf((*Repo)(nil)):                true,
+		reflect.TypeOf((*Service)(nil)):             false,
+		reflect.TypeOf((*EventHandler)(nil)).Elem(): false,
+		reflect.TypeOf((*Tx)(nil)):                  true,
+		reflect.TypeOf((*Session)(nil)):             false,
+		reflect.TypeOf((*Store)(nil)):               true,
+		reflect.TypeOf((*Pool)(nil)):                true,
+		reflect.TypeOf((*Dispatcher)(nil)):          true,
+	} {
+		if _, ok := c.GetTyped(typ); ok != expected {
+			t.Fatal(typ, ok)
+		}
+	}
+	if ok, condition := c.Matches(r, OnProperty("store.kind", "file")); ok || condition.String() != "on-property store.kind=file" {
+		t.Fatal(ok, condition)
+	}
+}
+
+func TestGeneric(t *testing.T) {
+	c := NewContainer()
+	a := &HandlerA{}
+	c.RegisterTyped(TypeOf[EventHandler](), a)
+	c.RegisterNamed("repo", &Repo{DSN: "repo"})
+	if h, ok := Typed[EventHandler](c); !ok || h != a {
+		t.Fatal(h, ok)
+	}
+	if repo, ok := Named[*Repo](c, "repo"); !ok || repo.DSN != "repo" {
+		t.Fatal(repo, ok)
+	}
+	if _, ok := Named[*Service](c, "repo"); ok {
+		t.Fatal("bean of another type")
+	}
+	if _, ok := Typed[*Service](c); ok {
+		t.Fatal("bean not registered")
+	}
+}
+
+type tracedHandler struct {
+	EventHandler
+}
+
+func (h *tracedHandler) Handle() string { return "traced " + h.EventHandler.Handle() }
+
+type Tracing struct{}
+
+func (*Tracing) BeforeInit(bean interface{}) (interface{}, error) { return nil, nil }
+
+func (*Tracing) AfterInit(bean interface{}) (interface{}, error) {
+	if h, ok := bean.(EventHandler); ok {
+		return &tracedHandler{h}, nil
+	}
+	return nil, nil
+}
+
+type Validation struct{}
+
+func (*Validation) BeforeInit(bean interface{}) (interface{}, error) {
+	if repo, ok := bean.(*Repo); ok && repo.DSN == "" {
+		return nil, fmt.Errorf("DSN is required")
+	}
+	return nil, nil
+}
+
+func (*Validation) AfterInit(bean interface{}) (interface{}, error) { return nil, nil }
+
+func (*Validation) Order() int { return 0 }
+
+type Repository struct {
+	Repo *Repo `@siu:""`
+}
+
+func TestPostProcessor(t *testing.T) {
+	c := NewContainer()
+	c.AddPostProcessor(&Tracing{}, &Validation{})
+	c.Provide(func() EventHandler { return &HandlerA{} })
+	s := &GraphService{}
+	if err := c.Inject(&NopValueResolver{}, s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Handler.Handle() != "traced a" {
+		t.Fatal(s.Handler.Handle())
+	}
+
+	bean, err := c.Process(&NopValueResolver{}, &HandlerB{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bean.(EventHandler).Handle() != "traced b" {
+		t.Fatal(bean)
+	}
+
+	err = c.Inject(&NopValueResolver{}, &Repository{})
+	if err == nil || err.Error() != "failed to create inject.Repository.Repo -> *inject.Repo: DSN is required" {
+		t.Fatal(err)
+	}
+
+	// a value holding a map is not comparable
+	c.AddPostProcessor(&Passthrough{})
+	c.Provide(func() Options { return Options{Value: map[string]int{"a": 1}} })
+	if options, ok := Typed[Options](c); !ok || options.Value.(map[string]int)["a"] != 1 {
+		t.Fatal(options, ok)
+	}
+}
+
+type Options struct {
+	Value interface{}
+}
+
+// Passthrough returns the beans as they are.
+type Passthrough struct{}
+
+func (*Passthrough) BeforeInit(bean interface{}) (interface{}, error) { return bean, nil }
+
+func (*Passthrough) AfterInit(bean interface{}) (interface{}, error) { return bean, nil }
+
+type LazyHandler struct {
+	Service *Lazy[*Service]     `@siu:""`
+	Events  Lazy[EventHandler]  `@siu:"name='events'"`
+	Missing *Lazy[EventHandler] `@siu:"name='missing'"`
+}
+
+func TestLazy(t *testing.T) {
+	c := NewContainer()
+	calls := 0
+	c.Provide(func() *Repo { calls++; return &Repo{DSN: "lazy"} }, WithLazy())
+	c.Provide(func(repo *Repo) *Service { return &Service{Repo: repo} }, WithLazy())
+	c.Provide(func() EventHandler { return &HandlerA{} }, WithNameOnly("events"), WithLazy())
+	h := &LazyHandler{}
+	if err := c.Inject(&NopValueResolver{}, h); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ResolveProviders(); err != nil || calls != 0 {
+		t.Fatal(err, calls)
+	}
+	if _, ok := c.GetTyped(TypeOf[EventHandler]()); ok {
+		t.Fatal("provided by name only")
+	}
+	d := &Dispatcher{}
+	if err := c.Inject(&NopValueResolver{}, d); err != nil || len(d.Handlers) != 0 || calls != 0 {
+		t.Fatal(err, d.Handlers, calls)
+	}
+
+	service, err := h.Service.Get()
+	if err != nil || service.Repo.DSN != "lazy" || calls != 1 {
+		t.Fatal(service, err, calls)
+	}
+	if again := h.Service.MustGet(); again != service {
+		t.Fatal(again)
+	}
+	if h.Events.MustGet().Handle() != "a" {
+		t.Fatal("events")
+	}
+	d = &Dispatcher{}
+	if err := c.Inject(&NopValueResolver{}, d); err != nil || len(d.Handlers) != 1 || d.ByName["events"] != h.Events.MustGet() {
+		t.Fatal(err, d.Handlers)
+	}
+	if _, err := h.Missing.Get(); err == nil || err.Error() != "failed to create inject.LazyHandler.Missing -> inject.EventHandler: typed object not found" {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/stella-go/siu/common"
+)
+
+// Lazy is an injection point which looks up or creates its bean on the first Get instead of when the object is
+// injected, e.g.
+//
+//	Storage *inject.Lazy[*s3.S3] `@siu:""`
+//
+// The options of the tag apply to the bean. A bean which fails is looked up again on the next Get.
+type Lazy[T any] struct {
+	lock    sync.Mutex
+	resolve func() (reflect.Value, error)
+	done    bool
+	value   T
+}
+
+// Get returns the bean, it is looked up on the first call.
+func (l *Lazy[T]) Get() (T, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	var zero T
+	if l.done {
+		return l.value, nil
+	}
+	if l.resolve == nil {
+		return zero, fmt.Errorf("lazy %s is not injected", TypeOf[T]())
+	}
+	v, err := l.resolve()
+	if err != nil {
+		return zero, err
+	}
+	if v.IsValid() && !v.IsZero() {
+		value, ok := unbox[T](v, true)
+		if !ok {
+			return zero, fmt.Errorf("object of type %s is not a %s", v.Type(), TypeOf[T]())
+		}
+		l.value = value
+	}
+	l.done = true
+	return l.value, nil
+}
+
+// MustGet returns the bean, it panics when the bean can not be looked up.
+func (l *Lazy[T]) MustGet() T {
+	value, err := l.Get()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (l *Lazy[T]) elemType() reflect.Type {
+	return TypeOf[T]()
+}
+
+func (l *Lazy[T]) bind(resolve func() (reflect.Value, error)) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	var zero T
+	l.resolve, l.done, l.value = resolve, false, zero
+}
+
+// lazy is implemented by *Lazy of any type.
+type lazy interface {
+	elemType() reflect.Type
+	bind(resolve func() (reflect.Value, error))
+}
+
+var lazyType = reflect.TypeOf((*lazy)(nil)).Elem()
+
+// lazyField returns the Lazy of a field typed *Lazy[T] or Lazy[T], a nil pointer is set to a new Lazy.
+func lazyField(val reflect.Value) (lazy, bool) {
+	if val.Kind() == reflect.Ptr && val.Type().Implements(lazyType) {
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		return val.Interface().(lazy), true
+	}
+	if val.CanAddr() && reflect.PtrTo(val.Type()).Implements(lazyType) {
+		return val.Addr().Interface().(lazy), true
+	}
+	return nil, false
+}
+
+// bindLazy binds the Lazy to the lookup of its bean, which is resolved like a field of the bean type with the
+// same tag in the object being injected.
+func (c *Container) bindLazy(r ValueResolver, field reflect.StructField, l lazy, visited *visit) {
+	owner := visited.top().typ
+	field.Type = l.elemType()
+	l.bind(func() (reflect.Value, error) {
+		visited := &visit{objects: make(map[reflect.Type]reflect.Value)}
+		visited.push(owner).field = field.Name
+		value := reflect.New(field.Type).Elem()
+		if err := c.setValue(r, field, value, visited); err != nil {
+			return reflect.Value{}, prependPath(owner.Elem().String()+"."+field.Name, err)
+		}
+		return value, nil
+	})
+	common.DEBUG("Field %s is injected lazily", field.Name)
+}
@@ -0,0 +1,107 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/stella-go/siu/common"
+	"github.com/stella-go/siu/interfaces"
+)
+
+// BeanPostProcessor is called for every object the container injects, before and after its Init, and for every
+// provided bean once it is constructed. The returned object replaces the bean, a nil object keeps it, and an
+// error aborts the injection.
+type BeanPostProcessor interface {
+	BeforeInit(bean interface{}) (interface{}, error)
+	AfterInit(bean interface{}) (interface{}, error)
+}
+
+// AddPostProcessor adds the processors, they are called in the order of interfaces.Order, the processors
+// without order are called last in the order they are added.
+func (c *Container) AddPostProcessor(processors ...BeanPostProcessor) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.processors = append(c.processors, processors...)
+	sort.SliceStable(c.processors, func(i, j int) bool {
+		return processorOrder(c.processors[i]) < processorOrder(c.processors[j])
+	})
+}
+
+func processorOrder(p BeanPostProcessor) int {
+	if o, ok := p.(interfaces.Order); ok {
+		return o.Order()
+	}
+	return math.MaxInt
+}
+
+// postProcess passes the bean through the processors, before or after its Init.
+func (c *Container) postProcess(bean interface{}, after bool) (interface{}, error) {
+	c.lock.Lock()
+	processors := c.processors
+	c.lock.Unlock()
+	for _, p := range processors {
+		var processed interface{}
+		var err error
+		if after {
+			processed, err = p.AfterInit(bean)
+		} else {
+			processed, err = p.BeforeInit(bean)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if processed != nil {
+			if identity(reflect.ValueOf(processed)) != identity(reflect.ValueOf(bean)) {
+				common.DEBUG("Object %T is replaced with %T by %T", bean, processed, p)
+			}
+			bean = processed
+		}
+	}
+	return bean, nil
+}
+
+// postProcessProvided passes a constructed bean through the processors, the result must still be assignable to
+// the type of the provider.
+func (c *Container) postProcessProvided(p *provider, v reflect.Value) (reflect.Value, error) {
+	c.lock.Lock()
+	n := len(c.processors)
+	c.lock.Unlock()
+	if n == 0 || !v.IsValid() || !v.CanInterface() {
+		return v, nil
+	}
+	bean, err := c.postProcess(v.Interface(), false)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if bean, err = c.postProcess(bean, true); err != nil {
+		return reflect.Value{}, err
+	}
+	return assignable(bean, p.typ)
+}
+
+// assignable returns the object as a value of the type.
+func assignable(obj interface{}, typ reflect.Type) (reflect.Value, error) {
+	value := reflect.ValueOf(obj)
+	if !value.IsValid() || !value.Type().AssignableTo(typ) {
+		return reflect.Value{}, fmt.Errorf("post processed object %T is not assignable to %s", obj, typ)
+	}
+	v := reflect.New(typ).Elem()
+	v.Set(value)
+	return v, nil
+}
@@ -0,0 +1,393 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/stella-go/siu/common"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// DependencyError is returned when a bean can not be created, Path is the chain of the beans being created,
+// from the bean being injected to the one which failed.
+type DependencyError struct {
+	Path []string
+	Err  error
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("failed to create %s: %v", strings.Join(e.Path, " -> "), e.Err)
+}
+
+func (e *DependencyError) Unwrap() error {
+	return e.Err
+}
+
+// prependPath returns a DependencyError with the bean added to the path of err.
+func prependPath(bean string, err error) error {
+	var de *DependencyError
+	if errors.As(err, &de) {
+		return &DependencyError{Path: append([]string{bean}, de.Path...), Err: de.Err}
+	}
+	return &DependencyError{Path: []string{bean}, Err: err}
+}
+
+// ProvideOption configures a constructor registered by Provide.
+type ProvideOption func(*provider)
+
+// WithName registers the bean under the name as well as under its type.
+func WithName(name string) ProvideOption {
+	return func(p *provider) {
+		p.name = name
+	}
+}
+
+// WithNameOnly registers the bean under the name only, several beans of the same type can be provided by name.
+func WithNameOnly(name string) ProvideOption {
+	return func(p *provider) {
+		p.name = name
+		p.untyped = true
+	}
+}
+
+// WithLazy leaves the singleton to its first lookup, it is not created by ResolveProviders at startup.
+func WithLazy() ProvideOption {
+	return func(p *provider) {
+		p.lazy = true
+	}
+}
+
+// WithScope sets the scope of the bean, ScopeSingleton by default.
+func WithScope(scope string) ProvideOption {
+	return func(p *provider) {
+		p.scope = scope
+	}
+}
+
+// WithConditions registers the bean only when all the conditions match, they are evaluated by
+// ActivateProviders.
+func WithConditions(conditions ...Condition) ProvideOption {
+	return func(p *provider) {
+		p.conditions = append(p.conditions, conditions...)
+	}
+}
+
+// WithParamNames resolves the parameters of the constructor by the names in order, an empty name resolves the
+// parameter by its type.
+func WithParamNames(names ...string) ProvideOption {
+	return func(p *provider) {
+		p.params = names
+	}
+}
+
+// provider creates a bean with a constructor, the constructor is called once on the first lookup of the bean.
+type provider struct {
+	constructor reflect.Value
+	typ         reflect.Type
+	name        string
+	params      []string
+	scope       string
+	conditions  []Condition
+	untyped     bool
+	lazy        bool
+
+	lock  sync.Mutex
+	done  bool
+	value reflect.Value
+	err   error
+}
+
+func (p *provider) String() string {
+	if p.name != "" {
+		return fmt.Sprintf("%s(\"%s\")", p.typ, p.name)
+	}
+	return p.typ.String()
+}
+
+// Provide registers a constructor like func(db *gorm.DB, logger interfaces.Logger) (*UserService, error),
+// the error result is optional. The bean is registered under the type of the first result, the parameters are
+// resolved from the container by type, or by name with WithParamNames.
+func Provide(constructor interface{}, options ...ProvideOption) error {
+	return defaultContainer.Provide(constructor, options...)
+}
+
+func (c *Container) Provide(constructor interface{}, options ...ProvideOption) error {
+	v := reflect.ValueOf(constructor)
+	typ := v.Type()
+	if typ.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Errorf("constructor must be a function, got %T", constructor)
+	}
+	if typ.IsVariadic() || typ.NumOut() < 1 || typ.NumOut() > 2 || (typ.NumOut() == 2 && typ.Out(1) != errorType) {
+		return fmt.Errorf("constructor %s must return a bean and optionally an error", typ)
+	}
+	p := &provider{constructor: v, typ: typ.Out(0), scope: ScopeSingleton}
+	for _, option := range options {
+		option(p)
+	}
+	if p.untyped && p.name == "" {
+		return fmt.Errorf("constructor %s is provided by name only without a name", typ)
+	}
+	if p.scope != ScopeSingleton && p.scope != ScopePrototype && p.scope != ScopeRequest {
+		return fmt.Errorf("constructor %s has unknown scope %s", typ, p.scope)
+	}
+	if len(p.params) > typ.NumIn() {
+		return fmt.Errorf("constructor %s has %d parameters, got %d names", typ, typ.NumIn(), len(p.params))
+	}
+	if len(p.conditions) > 0 {
+		c.lock.Lock()
+		c.pending = append(c.pending, p)
+		c.lock.Unlock()
+		common.DEBUG("Provider of %s is pending on its conditions", p)
+		return nil
+	}
+	return c.addProvider(p)
+}
+
+func (c *Container) addProvider(p *provider) error {
+	if !p.untyped {
+		if _, ok := c.typed.Load(p.typ); ok {
+			return fmt.Errorf("typed object %s is already registered", p.typ)
+		}
+		if _, ok := c.providers.LoadOrStore(p.typ, p); ok {
+			return fmt.Errorf("typed object %s is already provided", p.typ)
+		}
+	}
+	if p.name != "" {
+		if _, ok := c.named.Load(p.name); ok {
+			if !p.untyped {
+				c.providers.Delete(p.typ)
+			}
+			return fmt.Errorf("named object \"%s\" is already registered", p.name)
+		}
+		if _, ok := c.namedProviders.LoadOrStore(p.name, p); ok {
+			if !p.untyped {
+				c.providers.Delete(p.typ)
+			}
+			return fmt.Errorf("named object \"%s\" is already provided", p.name)
+		}
+	}
+	c.lock.Lock()
+	c.providerList = append(c.providerList, p)
+	c.lock.Unlock()
+	c.graph.addNode(p.typ.String(), p.name, p.scope)
+	common.DEBUG("Provider of %s registered", p)
+	return nil
+}
+
+// ActivateProviders evaluates the conditions of the pending providers registered WithConditions, in the order they
+// were provided. The providers whose conditions match are registered, the others stay pending and are evaluated
+// again by the next call, e.g. once the auto factories have registered their beans.
+func (c *Container) ActivateProviders(r ValueResolver) error {
+	c.lock.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.lock.Unlock()
+	for i, p := range pending {
+		if ok, condition := c.Matches(r, p.conditions...); !ok {
+			common.DEBUG("Provider of %s is pending, %s does not match", p, condition)
+			c.lock.Lock()
+			c.pending = append(c.pending, p)
+			c.lock.Unlock()
+			continue
+		}
+		if err := c.addProvider(p); err != nil {
+			c.lock.Lock()
+			c.pending = append(c.pending, pending[i+1:]...)
+			c.lock.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveProviders calls the constructors of the singletons which have not been called yet, in the order they
+// were provided, the lazy singletons are left to their first lookup.
+func (c *Container) ResolveProviders() error {
+	c.lock.Lock()
+	providers := append([]*provider{}, c.providerList...)
+	c.lock.Unlock()
+	for _, p := range providers {
+		if p.scope != ScopeSingleton || p.lazy {
+			continue
+		}
+		if _, err := c.callProvider(p, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Singletons returns the provided singletons created so far, in the order they were provided.
+func (c *Container) Singletons() []interface{} {
+	c.lock.Lock()
+	providers := append([]*provider{}, c.providerList...)
+	c.lock.Unlock()
+	beans := make([]interface{}, 0)
+	for _, p := range providers {
+		if p.scope != ScopeSingleton {
+			continue
+		}
+		p.lock.Lock()
+		if p.done && p.err == nil && p.value.IsValid() && p.value.CanInterface() {
+			beans = append(beans, p.value.Interface())
+		}
+		p.lock.Unlock()
+	}
+	return beans
+}
+
+// resolution is the state of a lookup: the request scope of the lookup, if any, and the providers being created.
+type resolution struct {
+	scope    *RequestScope
+	creating []*provider
+}
+
+func (r *resolution) enter(p *provider) (*resolution, error) {
+	if r == nil {
+		r = &resolution{}
+	}
+	for _, q := range r.creating {
+		if q == p {
+			return nil, &DependencyError{Path: []string{p.String()}, Err: fmt.Errorf("circular dependency")}
+		}
+	}
+	creating := append(append(make([]*provider, 0, len(r.creating)+1), r.creating...), p)
+	if p.scope == ScopeSingleton {
+		// a singleton outlives the request, it can not depend on the request scoped beans
+		return &resolution{creating: creating}, nil
+	}
+	return &resolution{scope: r.scope, creating: creating}, nil
+}
+
+// callProvider returns the bean of the provider by its scope. A singleton is created on the first call and its
+// result or error is kept, a prototype is created on every call, a request scoped bean once per request scope.
+func (c *Container) callProvider(p *provider, r *resolution) (reflect.Value, error) {
+	next, err := r.enter(p)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	switch p.scope {
+	case ScopePrototype:
+		return c.construct(p, next)
+	case ScopeRequest:
+		if next.scope == nil {
+			return reflect.Value{}, &DependencyError{Path: []string{p.String()}, Err: fmt.Errorf("request scoped object is looked up outside a request")}
+		}
+		return next.scope.get(p, next)
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.done {
+		return p.value, p.err
+	}
+	p.value, p.err = c.construct(p, next)
+	p.done = true
+	if p.err != nil {
+		return reflect.Value{}, p.err
+	}
+	if !p.untyped {
+		c.typed.Store(p.typ, p.value)
+	}
+	if p.name != "" {
+		c.named.Store(p.name, p.value)
+	}
+	c.track(p.value)
+	common.DEBUG("Typed object %s provided", p)
+	return p.value, nil
+}
+
+func (c *Container) construct(p *provider, r *resolution) (reflect.Value, error) {
+	typ := p.constructor.Type()
+	args := make([]reflect.Value, typ.NumIn())
+	for i := range args {
+		name := ""
+		if i < len(p.params) {
+			name = p.params[i]
+		}
+		arg, err := c.resolveParam(typ.In(i), name, r)
+		if err != nil {
+			return reflect.Value{}, prependPath(p.String(), err)
+		}
+		args[i] = arg
+		if id, ok := nodeID(arg); ok {
+			c.graph.addEdge(p.typ.String(), fmt.Sprintf("#%d", i), id, false)
+		}
+	}
+	common.DEBUG("Call the constructor of %s", p)
+	out := p.constructor.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return reflect.Value{}, &DependencyError{Path: []string{p.String()}, Err: out[1].Interface().(error)}
+	}
+	v, err := c.postProcessProvided(p, out[0])
+	if err != nil {
+		return reflect.Value{}, &DependencyError{Path: []string{p.String()}, Err: err}
+	}
+	return v, nil
+}
+
+func (c *Container) resolveParam(typ reflect.Type, name string, r *resolution) (reflect.Value, error) {
+	if name != "" {
+		v, ok, err := c.loadNamed(name, r)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !ok {
+			return reflect.Value{}, &DependencyError{Path: []string{fmt.Sprintf("%s(\"%s\")", typ, name)}, Err: fmt.Errorf("named object not found")}
+		}
+		if !v.Type().AssignableTo(typ) {
+			return reflect.Value{}, &DependencyError{Path: []string{fmt.Sprintf("%s(\"%s\")", typ, name)}, Err: fmt.Errorf("named object of type %s is not assignable", v.Type())}
+		}
+		return v, nil
+	}
+	v, ok, err := c.loadTyped(typ, r)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if !ok {
+		return reflect.Value{}, &DependencyError{Path: []string{typ.String()}, Err: fmt.Errorf("typed object not found")}
+	}
+	return v, nil
+}
+
+// loadTyped returns the bean of the type, the provider of the type is called by its scope. Without either the
+// primary bean assignable to the type is returned.
+func (c *Container) loadTyped(typ reflect.Type, r *resolution) (reflect.Value, bool, error) {
+	if v, ok := c.typed.Load(typ); ok {
+		return v.(reflect.Value), true, nil
+	}
+	if p, ok := c.providers.Load(typ); ok {
+		v, err := c.callProvider(p.(*provider), r)
+		return v, err == nil, err
+	}
+	return c.loadPrimary(typ, r)
+}
+
+// loadNamed returns the bean of the name, the provider of the name is called by its scope.
+func (c *Container) loadNamed(name string, r *resolution) (reflect.Value, bool, error) {
+	if v, ok := c.named.Load(name); ok {
+		return v.(reflect.Value), true, nil
+	}
+	if p, ok := c.namedProviders.Load(name); ok {
+		v, err := c.callProvider(p.(*provider), r)
+		return v, err == nil, err
+	}
+	return reflect.Value{}, false, nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/stella-go/siu/interfaces"
+)
+
+var primaryType = reflect.TypeOf((*interfaces.Primary)(nil)).Elem()
+
+// loadPrimary returns the bean marked by interfaces.Primary among the beans assignable to the type, it is used
+// when no bean is registered under the type itself.
+func (c *Container) loadPrimary(typ reflect.Type, r *resolution) (reflect.Value, bool, error) {
+	c.lock.Lock()
+	providers := append([]*provider{}, c.providerList...)
+	c.lock.Unlock()
+	for _, p := range providers {
+		if p.scope == ScopeSingleton && p.typ.AssignableTo(typ) && p.typ.Implements(primaryType) {
+			if _, err := c.callProvider(p, r); err != nil {
+				return reflect.Value{}, false, err
+			}
+		}
+	}
+
+	seen := make(map[interface{}]struct{})
+	names := make([]string, 0)
+	var primary reflect.Value
+	add := func(name string, v reflect.Value) {
+		v = unwrap(v)
+		if !v.IsValid() || !v.Type().AssignableTo(typ) || !v.Type().Implements(primaryType) {
+			return
+		}
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			return
+		}
+		if !v.Interface().(interfaces.Primary).Primary() {
+			return
+		}
+		id := identity(v)
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+		names = append(names, name)
+		primary = v
+	}
+	c.named.Range(func(k, v interface{}) bool {
+		add(fmt.Sprintf("\"%s\"", k), v.(reflect.Value))
+		return true
+	})
+	c.typed.Range(func(k, v interface{}) bool {
+		add(k.(reflect.Type).String(), v.(reflect.Value))
+		return true
+	})
+	switch len(names) {
+	case 0:
+		return reflect.Value{}, false, nil
+	case 1:
+		return primary, true, nil
+	}
+	sort.Strings(names)
+	return reflect.Value{}, false, &DependencyError{Path: []string{typ.String()}, Err: fmt.Errorf("more than one primary object: %s", strings.Join(names, ", "))}
+}
+
+// loadQualified returns the bean of the name, or the only bean assignable to the type whose name ends with
+// "." and the qualifier, e.g. the qualifier reporting matches the datasource gorm.reporting.
+func (c *Container) loadQualified(qualifier string, typ reflect.Type, r *resolution) (reflect.Value, bool, error) {
+	if v, ok, err := c.loadNamed(qualifier, r); err != nil || ok {
+		return v, ok, err
+	}
+	suffix := "." + qualifier
+	matched := make(map[string]struct{})
+	c.named.Range(func(k, v interface{}) bool {
+		value := unwrap(v.(reflect.Value))
+		if strings.HasSuffix(k.(string), suffix) && value.IsValid() && value.Type().AssignableTo(typ) {
+			matched[k.(string)] = struct{}{}
+		}
+		return true
+	})
+	c.namedProviders.Range(func(k, v interface{}) bool {
+		if strings.HasSuffix(k.(string), suffix) && maybeAssignable(v.(*provider).typ, typ) {
+			matched[k.(string)] = struct{}{}
+		}
+		return true
+	})
+	names := make([]string, 0, len(matched))
+	for name := range matched {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	switch len(names) {
+	case 0:
+		return reflect.Value{}, false, nil
+	case 1:
+		return c.loadNamed(names[0], r)
+	}
+	return reflect.Value{}, false, &DependencyError{Path: []string{fmt.Sprintf("%s(\"%s\")", typ, qualifier)}, Err: fmt.Errorf("qualifier matches more than one object: %s", strings.Join(names, ", "))}
+}
+
+func unwrap(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
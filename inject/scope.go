@@ -0,0 +1,140 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/stella-go/siu/common"
+)
+
+// The scopes of the provided beans.
+const (
+	// ScopeSingleton beans are created once per container
+	ScopeSingleton = "singleton"
+	// ScopePrototype beans are created on every lookup, i.e. for every injection point
+	ScopePrototype = "prototype"
+	// ScopeRequest beans are created once per RequestScope, they can not be injected into singletons
+	ScopeRequest = "request"
+)
+
+// RequestScope holds the request scoped beans of a request, the beans implementing Disposable or io.Closer are
+// destroyed by Close.
+type RequestScope struct {
+	c     *Container
+	lock  sync.Mutex
+	beans map[*provider]*scoped
+	order []reflect.Value
+}
+
+// scoped is a request scoped bean, its lock is held while the bean is created so that concurrent lookups in the
+// request create it once.
+type scoped struct {
+	lock  sync.Mutex
+	done  bool
+	value reflect.Value
+	err   error
+}
+
+func (c *Container) NewRequestScope() *RequestScope {
+	return &RequestScope{c: c}
+}
+
+// GetTyped returns the reflect.Value of the bean, the request scoped beans are created once in the scope.
+func (s *RequestScope) GetTyped(refType reflect.Type) (interface{}, bool) {
+	v, ok, err := s.c.loadTyped(refType, &resolution{scope: s})
+	if err != nil {
+		common.ERROR("Get typed object %s with error:", refType, err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	return v, true
+}
+
+// GetNamed returns the reflect.Value of the bean, the request scoped beans are created once in the scope.
+func (s *RequestScope) GetNamed(name string) (interface{}, bool) {
+	v, ok, err := s.c.loadNamed(name, &resolution{scope: s})
+	if err != nil {
+		common.ERROR("Get named object \"%s\" with error:", name, err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	return v, true
+}
+
+func (s *RequestScope) get(p *provider, r *resolution) (reflect.Value, error) {
+	s.lock.Lock()
+	if s.beans == nil {
+		s.beans = make(map[*provider]*scoped)
+	}
+	bean, ok := s.beans[p]
+	if !ok {
+		bean = &scoped{}
+		s.beans[p] = bean
+	}
+	s.lock.Unlock()
+
+	bean.lock.Lock()
+	defer bean.lock.Unlock()
+	if bean.done {
+		return bean.value, bean.err
+	}
+	bean.value, bean.err = s.c.construct(p, r)
+	bean.done = true
+	if bean.err != nil {
+		return reflect.Value{}, bean.err
+	}
+	s.lock.Lock()
+	s.order = append(s.order, bean.value)
+	s.lock.Unlock()
+	common.DEBUG("Request scoped object %s provided", p)
+	return bean.value, nil
+}
+
+// Close destroys or closes the beans of the scope in the reverse order of their creation, all the beans are
+// closed even if some of them fail.
+func (s *RequestScope) Close() error {
+	s.lock.Lock()
+	order := s.order
+	s.beans, s.order = nil, nil
+	s.lock.Unlock()
+	messages := make([]string, 0)
+	for i := len(order) - 1; i >= 0; i-- {
+		if !order[i].IsValid() || !order[i].CanInterface() {
+			continue
+		}
+		if _, ok := order[i].Interface().(Disposable); ok {
+			if err := destroy(order[i], 0); err != nil {
+				messages = append(messages, fmt.Sprintf("%s: %v", order[i].Type(), err))
+			}
+		} else if closer, ok := order[i].Interface().(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				messages = append(messages, fmt.Sprintf("%s: %v", order[i].Type(), err))
+			}
+		}
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("failed to close request scoped objects: %s", strings.Join(messages, "; "))
+	}
+	return nil
+}
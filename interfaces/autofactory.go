@@ -27,3 +27,18 @@ type AutoFactory interface {
 	Named() map[string]interface{}
 	Typed() map[reflect.Type]interface{}
 }
+
+// LazyFactory is an AutoFactory which, when Lazy returns true, is started on the first lookup of one of its beans
+// instead of at startup. Declared returns the types and the names of the beans it registers once started.
+type LazyFactory interface {
+	AutoFactory
+	Lazy() bool
+	Declared() ([]reflect.Type, map[string]reflect.Type)
+}
+
+// BootstrapFactory is an AutoFactory started once the configuration is validated, before the beans are registered,
+// e.g. the cipher decrypting the ENC(...) values. It is injected with the built-in beans only.
+type BootstrapFactory interface {
+	AutoFactory
+	Bootstrap()
+}
@@ -28,6 +28,12 @@ type LeveledLogger interface {
 	Level() logger.Level
 }
 
+// MutableLeveledLogger changes its level at runtime, e.g. when logger.level is reloaded
+type MutableLeveledLogger interface {
+	LeveledLogger
+	SetLevel(logger.Level)
+}
+
 type TagedLogger interface {
 	Logger
 	Tag() string
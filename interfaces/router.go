@@ -26,3 +26,14 @@ type MiddlewareRouter interface {
 	Router
 	Middleware() []gin.HandlerFunc
 }
+
+const (
+	ServerListener     = "server"
+	ManagementListener = "management"
+)
+
+// ListenerRouter declares the listener the routers belong to, ServerListener or ManagementListener.
+type ListenerRouter interface {
+	Router
+	Listener() string
+}
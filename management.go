@@ -0,0 +1,83 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package siu
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stella-go/siu/config"
+)
+
+const (
+	ManagementKey               = "management"
+	ManagementIPKey             = ManagementKey + ".ip"
+	ManagementPortKey           = ManagementKey + ".port"
+	ManagementPrefixKey         = ManagementKey + ".prefix"
+	ManagementUnixSocketKey     = ManagementKey + ".unix-socket"
+	ManagementPprofDisableKey   = ManagementKey + ".pprof.disable"
+	ManagementMetricsDisableKey = ManagementKey + ".metrics.disable"
+	ManagementConfigDisableKey  = ManagementKey + ".config.disable"
+	ManagementBeansDisableKey   = ManagementKey + ".beans.disable"
+
+	ServerUnixSocketKey = "server.unix-socket"
+)
+
+// newManagement creates the management engine when a management port or unix socket is configured.
+func (c *Application) newManagement() *gin.Engine {
+	_, ok1 := c.environment.GetString(ManagementPortKey)
+	_, ok2 := c.environment.GetString(ManagementUnixSocketKey)
+	if !ok1 && !ok2 {
+		return nil
+	}
+	management := gin.New()
+	management.SetTrustedProxies(nil)
+	management.Use(gin.Recovery())
+	return management
+}
+
+func (c *Application) routeManagement(group *gin.RouterGroup) {
+	if !c.environment.GetBoolOr(ManagementPprofDisableKey, false) {
+		group.GET("/debug/pprof/", gin.WrapF(pprof.Index))
+		group.GET("/debug/pprof/cmdline", gin.WrapF(pprof.Cmdline))
+		group.GET("/debug/pprof/profile", gin.WrapF(pprof.Profile))
+		group.POST("/debug/pprof/symbol", gin.WrapF(pprof.Symbol))
+		group.GET("/debug/pprof/symbol", gin.WrapF(pprof.Symbol))
+		group.GET("/debug/pprof/trace", gin.WrapF(pprof.Trace))
+		group.GET("/debug/pprof/:name", func(ctx *gin.Context) {
+			pprof.Handler(ctx.Param("name")).ServeHTTP(ctx.Writer, ctx.Request)
+		})
+	}
+	if !c.environment.GetBoolOr(ManagementMetricsDisableKey, false) {
+		group.GET("/metrics", gin.WrapH(expvar.Handler()))
+	}
+	if !c.environment.GetBoolOr(ManagementConfigDisableKey, false) {
+		group.GET("/config", func(ctx *gin.Context) {
+			ctx.JSON(http.StatusOK, config.DumpOf(c.environment))
+		})
+	}
+	if !c.environment.GetBoolOr(ManagementBeansDisableKey, false) {
+		group.GET("/beans", func(ctx *gin.Context) {
+			graph := c.container.Graph()
+			if ctx.Query("format") == "dot" {
+				ctx.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(graph.DOT()))
+				return
+			}
+			ctx.JSON(http.StatusOK, graph)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package siu
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stella-go/logger"
+)
+
+func newTestApplication(conf map[string]interface{}) *Application {
+	gin.SetMode(gin.TestMode)
+	return NewApplication(typedConfig(conf), newBuildinLogger(logger.ErrorLevel, "[TEST]", io.Discard), gin.New())
+}
+
+func TestNewManagement(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    map[string]interface{}
+		enabled bool
+	}{
+		{"disabled", map[string]interface{}{}, false},
+		{"port", map[string]interface{}{ManagementPortKey: "8081"}, true},
+		{"unix socket", map[string]interface{}{ManagementUnixSocketKey: "/tmp/management.sock"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if management := newTestApplication(tt.conf).newManagement(); (management != nil) != tt.enabled {
+				t.Fatal(management)
+			}
+		})
+	}
+}
+
+func TestRouteManagement(t *testing.T) {
+	paths := []string{"/manage/metrics", "/manage/config", "/manage/beans", "/manage/beans?format=dot", "/manage/debug/pprof/cmdline"}
+	tests := []struct {
+		name   string
+		conf   map[string]interface{}
+		status []int
+	}{
+		{"enabled", map[string]interface{}{}, []int{200, 200, 200, 200, 200}},
+		{"disabled", map[string]interface{}{
+			ManagementMetricsDisableKey: true,
+			ManagementConfigDisableKey:  true,
+			ManagementBeansDisableKey:   true,
+			ManagementPprofDisableKey:   true,
+		}, []int{404, 404, 404, 404, 404}},
+		{"beans only", map[string]interface{}{
+			ManagementMetricsDisableKey: true,
+			ManagementConfigDisableKey:  true,
+			ManagementPprofDisableKey:   true,
+		}, []int{404, 404, 200, 200, 404}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestApplication(tt.conf)
+			engine := gin.New()
+			c.routeManagement(engine.Group("/manage"))
+			for i, path := range paths {
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+				if w.Code != tt.status[i] {
+					t.Fatal(path, w.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestManagementConfig(t *testing.T) {
+	c := newTestApplication(map[string]interface{}{"app.name": "siu-test"})
+	engine := gin.New()
+	c.routeManagement(engine.Group(""))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config", nil))
+	if w.Code != http.StatusOK || w.Body.String() != `[{"key":"app.name","value":"siu-test","source":"map"}]` {
+		t.Fatal(w.Code, w.Body.String())
+	}
+}
+
+func TestServeUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "siu.sock")
+	// a socket file left by a previous process is replaced
+	os.WriteFile(socket, nil, 0600)
+	c := newTestApplication(map[string]interface{}{})
+	engine := gin.New()
+	engine.GET("/ping", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "pong")
+	})
+	server := c.serve(engine, "unix", socket, nil)
+	defer drain([]*http.Server{server}, time.Second)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	resp, err := client.Get("http://unix/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "pong" {
+		t.Fatal(string(body))
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 	"unicode"
 
@@ -49,19 +50,29 @@ func (w *CustomResponseWriter) WriteString(s string) (int, error) {
 	return w.ResponseWriter.WriteString(s)
 }
 
-type MiddlewareAccess struct {
-	Conf      config.TypedConfig `@siu:"name='environment',default='type'"`
-	Logger    interfaces.Logger  `@siu:"name='logger',default='type'"`
+type accessSettings struct {
 	debug     bool
 	maxLength int
 }
 
+type MiddlewareAccess struct {
+	Conf     config.TypedConfig `@siu:"name='environment',default='type'"`
+	Logger   interfaces.Logger  `@siu:"name='logger',default='type'"`
+	settings atomic.Value
+}
+
 func (p *MiddlewareAccess) Init() {
 	debug := p.Conf.GetStringOr("logger.level", "info")
-	if strings.ToLower(debug) == "debug" {
-		p.debug = true
+	p.settings.Store(&accessSettings{
+		debug:     strings.ToLower(debug) == "debug",
+		maxLength: p.Conf.GetIntOr(AccessMaxLengthKey, 2048),
+	})
+}
+
+func (p *MiddlewareAccess) OnConfigChange(keys []string) {
+	if config.ContainsKey(keys, "logger.level", AccessMaxLengthKey) {
+		p.Init()
 	}
-	p.maxLength = p.Conf.GetIntOr(AccessMaxLengthKey, 2048)
 }
 
 func (p *MiddlewareAccess) Condition() bool {
@@ -73,7 +84,8 @@ func (p *MiddlewareAccess) Condition() bool {
 
 func (p *MiddlewareAccess) Function() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !p.debug {
+		settings := p.settings.Load().(*accessSettings)
+		if !settings.debug {
 			start := time.Now()
 			method := c.Request.Method
 			path := c.Request.URL.Path
@@ -104,8 +116,8 @@ func (p *MiddlewareAccess) Function() gin.HandlerFunc {
 			if len(bts) > 0 {
 				s := string(bts)
 				if p.isPrintable(s) {
-					if len(bts) > p.maxLength {
-						s := fmt.Sprintf("\n=============::Request::=============\n%s %s %s\n\n%s\n%s...\n", method, path, proto, headers, bts[:p.maxLength])
+					if len(bts) > settings.maxLength {
+						s := fmt.Sprintf("\n=============::Request::=============\n%s %s %s\n\n%s\n%s...\n", method, path, proto, headers, bts[:settings.maxLength])
 						sb.WriteString(s)
 					} else {
 						s := fmt.Sprintf("\n=============::Request::=============\n%s %s %s\n\n%s\n%s\n", method, path, proto, headers, bts)
@@ -136,8 +148,8 @@ func (p *MiddlewareAccess) Function() gin.HandlerFunc {
 			if len(bts) > 0 {
 				s := string(bts)
 				if p.isPrintable(s) {
-					if len(bts) > p.maxLength {
-						s := fmt.Sprintf("=============::Response::============\n%s %d %s\n\n%s\n%s...\n", proto, status, statusText, headers, bts[:p.maxLength])
+					if len(bts) > settings.maxLength {
+						s := fmt.Sprintf("=============::Response::============\n%s %d %s\n\n%s\n%s...\n", proto, status, statusText, headers, bts[:settings.maxLength])
 						sb.WriteString(s)
 					} else {
 						s := fmt.Sprintf("=============::Response::============\n%s %d %s\n\n%s\n%s\n", proto, status, statusText, headers, bts)
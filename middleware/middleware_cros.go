@@ -16,6 +16,7 @@ package middleware
 
 import (
 	"net/http"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stella-go/siu/config"
@@ -28,15 +29,27 @@ const (
 	CROSMiddleOrder       = 20
 )
 
-type MiddlewareCROS struct {
-	Conf     config.TypedConfig `@siu:"name='environment',default='type'"`
+type crosSettings struct {
 	wildcard bool
 	expose   string
 }
 
+type MiddlewareCROS struct {
+	Conf     config.TypedConfig `@siu:"name='environment',default='type'"`
+	settings atomic.Value
+}
+
 func (p *MiddlewareCROS) Init() {
-	p.wildcard = p.Conf.GetBoolOr(CROSMiddleWildcardKey, true)
-	p.expose = p.Conf.GetStringOr(CROSMiddleExposedKey, "*")
+	p.settings.Store(&crosSettings{
+		wildcard: p.Conf.GetBoolOr(CROSMiddleWildcardKey, true),
+		expose:   p.Conf.GetStringOr(CROSMiddleExposedKey, "*"),
+	})
+}
+
+func (p *MiddlewareCROS) OnConfigChange(keys []string) {
+	if config.ContainsKey(keys, CROSMiddleWildcardKey, CROSMiddleExposedKey) {
+		p.Init()
+	}
 }
 
 func (p *MiddlewareCROS) Condition() bool {
@@ -48,7 +61,8 @@ func (p *MiddlewareCROS) Condition() bool {
 
 func (p *MiddlewareCROS) Function() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if p.wildcard {
+		settings := p.settings.Load().(*crosSettings)
+		if settings.wildcard {
 			c.Header("Access-Control-Allow-Origin", "*")
 			c.Header("Access-Control-Allow-Headers", "*")
 			c.Header("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, DELETE, OPTIONS")
@@ -67,7 +81,7 @@ func (p *MiddlewareCROS) Function() gin.HandlerFunc {
 			c.Header("Access-Control-Allow-Headers", headers)
 			c.Header("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, DELETE, OPTIONS")
 			c.Header("Access-Control-Allow-Credentials", "true")
-			c.Header("Access-Control-Expose-Headers", p.expose)
+			c.Header("Access-Control-Expose-Headers", settings.expose)
 		}
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -22,6 +22,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/stella-go/siu/common"
 	"github.com/stella-go/siu/config"
 	"github.com/stella-go/siu/t"
 )
@@ -58,21 +59,25 @@ type MiddlewareJwt struct {
 	excludes      []string
 }
 
+type jwtSettings struct {
+	CookieDomain  string   `config:"name='cookie-domain'"`
+	ExpireSeconds int      `config:"name='expire-seconds',default='3600'"`
+	Secret        string   `config:"name='secret'"`
+	Excludes      []string `config:"name='excludes',default='/login,/admin/login,/api/login'"`
+}
+
 func (p *MiddlewareJwt) Init() {
-	p.cookieDomain = p.Conf.GetStringOr(JwtCookiedomainKey, "")
-	p.expireSeconds = p.Conf.GetIntOr(JwtExpiresecondsKey, 3600)
-	p.secret = p.Conf.GetStringOr(JwtSecretKey, uuid.NewString())
-	excludes := p.Conf.GetOr(JwtExcludesKey, []string{"/login", "/admin/login", "/api/login"})
-	if e, ok := excludes.([]string); ok {
-		p.excludes = e
-	} else {
-		if e, ok := excludes.(string); ok {
-			splits := strings.Split(e, ",")
-			for _, s := range splits {
-				p.excludes = append(p.excludes, strings.TrimSpace(s))
-			}
-		}
+	settings := &jwtSettings{}
+	if err := config.BindFrom(p.Conf, JwtKey, settings); err != nil {
+		common.ERROR("Failed to bind configuration %s, with error", JwtKey, err)
+	}
+	if settings.Secret == "" {
+		settings.Secret = uuid.NewString()
 	}
+	p.cookieDomain = settings.CookieDomain
+	p.expireSeconds = settings.ExpireSeconds
+	p.secret = settings.Secret
+	p.excludes = settings.Excludes
 }
 
 func (p *MiddlewareJwt) Condition() bool {
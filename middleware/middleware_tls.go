@@ -0,0 +1,73 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stella-go/siu/config"
+)
+
+const (
+	TLSClientCAFileKey = "server.tls.client-ca-file"
+	TLSMiddleOrder     = 1
+
+	TLSClientCertificateContextKey = "tls-client-certificate"
+	TLSClientSubjectContextKey     = "tls-client-subject"
+)
+
+type MiddlewareTLS struct {
+	Conf config.TypedConfig `@siu:"name='environment',default='type'"`
+}
+
+func (p *MiddlewareTLS) Condition() bool {
+	_, ok := p.Conf.GetString(TLSClientCAFileKey)
+	return ok
+}
+
+func (p *MiddlewareTLS) Function() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if state := c.Request.TLS; state != nil && len(state.VerifiedChains) > 0 && len(state.VerifiedChains[0]) > 0 {
+			cert := state.VerifiedChains[0][0]
+			c.Set(TLSClientCertificateContextKey, cert)
+			c.Set(TLSClientSubjectContextKey, cert.Subject)
+		}
+		c.Next()
+	}
+}
+
+func (p *MiddlewareTLS) Order() int {
+	return TLSMiddleOrder
+}
+
+func GetClientCertificate(c *gin.Context) *x509.Certificate {
+	if value, ok := c.Get(TLSClientCertificateContextKey); ok {
+		if cert, ok := value.(*x509.Certificate); ok {
+			return cert
+		}
+	}
+	return nil
+}
+
+func GetClientSubject(c *gin.Context) (pkix.Name, bool) {
+	if value, ok := c.Get(TLSClientSubjectContextKey); ok {
+		if subject, ok := value.(pkix.Name); ok {
+			return subject, true
+		}
+	}
+	return pkix.Name{}, false
+}
@@ -0,0 +1,67 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package siu
+
+import (
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stella-go/siu/inject"
+)
+
+// RequestScopeKey is the gin.Context key of the *inject.RequestScope of the request.
+const RequestScopeKey = "siu.request-scope"
+
+// requestScope installs a request scope for every request, its beans are closed when the request ends.
+func (c *Application) requestScope() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scope := c.container.NewRequestScope()
+		ctx.Set(RequestScopeKey, scope)
+		defer func() {
+			if err := scope.Close(); err != nil {
+				c.logger.ERROR("%v", err)
+			}
+		}()
+		ctx.Next()
+	}
+}
+
+// RequestScope returns the request scope installed on the context.
+func RequestScope(ctx *gin.Context) (*inject.RequestScope, bool) {
+	v, ok := ctx.Get(RequestScopeKey)
+	if !ok {
+		return nil, false
+	}
+	scope, ok := v.(*inject.RequestScope)
+	return scope, ok
+}
+
+// GetRequestBeanByName looks up the bean in the request scope of the context, it returns false outside a request.
+func GetRequestBeanByName(ctx *gin.Context, name string) (interface{}, bool) {
+	scope, ok := RequestScope(ctx)
+	if !ok {
+		return nil, false
+	}
+	return scope.GetNamed(name)
+}
+
+// GetRequestBeanByType looks up the bean in the request scope of the context, it returns false outside a request.
+func GetRequestBeanByType(ctx *gin.Context, typ reflect.Type) (interface{}, bool) {
+	scope, ok := RequestScope(ctx)
+	if !ok {
+		return nil, false
+	}
+	return scope.GetTyped(typ)
+}
@@ -24,33 +24,42 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stella-go/siu/common"
 	"github.com/stella-go/siu/config"
+	"github.com/stella-go/siu/inject"
 	"github.com/stella-go/siu/interfaces"
 )
 
-var ctx *context
+var ctx *Application
 var once sync.Once
 
 // export method
 
+// DefaultApplication returns the default application used by the package level functions, its beans are kept in
+// inject.DefaultContainer.
+func DefaultApplication() *Application {
+	Default()
+	return ctx
+}
+
 func LoadConfig(files ...string) {
 	config.LoadConfig(files...)
 }
 
 func New(environment config.TypedConfig, contextLogger interfaces.Logger, server *gin.Engine) {
 	once.Do(func() {
-		ctx = newContext(environment, contextLogger, server)
+		ctx = newApplication(environment, contextLogger, server, inject.DefaultContainer())
 	})
 }
 
 func NewWithEnvironment(environment config.TypedConfig) {
 	once.Do(func() {
-		ctx = newEnvironmentContext(environment)
+		ctx = newApplication(environment, newContextLogger(environment), nil, inject.DefaultContainer())
 	})
 }
 
 func Default() {
 	once.Do(func() {
-		ctx = newDefaultContext()
+		environment := &config.ConfigurationEnvironment{}
+		ctx = newApplication(environment, newContextLogger(environment), nil, inject.DefaultContainer())
 	})
 }
 
@@ -102,9 +111,9 @@ func ERROR(format string, arr ...interface{}) {
 	}
 }
 
-func RegisterBean(name string, typ reflect.Type, obj interface{}) {
+func RegisterBean(name string, typ reflect.Type, obj interface{}, conditions ...inject.Condition) {
 	Default()
-	ctx.RegisterBean(name, typ, obj)
+	ctx.RegisterBean(name, typ, obj, conditions...)
 }
 
 func GetBeanByName(name string) (interface{}, bool) {
@@ -117,6 +126,35 @@ func GetBeanByType(typ reflect.Type) (interface{}, bool) {
 	return ctx.GetBeanByType(typ)
 }
 
+// RegisterAs registers the object by the type T only, e.g. siu.RegisterAs[redis.Cmdable](client).
+func RegisterAs[T any](obj T, conditions ...inject.Condition) {
+	RegisterBean("", inject.TypeOf[T](), obj, conditions...)
+}
+
+// Bean returns the bean registered or provided as T, e.g. db, ok := siu.Bean[*gorm.DB]().
+func Bean[T any]() (T, bool) {
+	return inject.Typed[T](DefaultApplication().Container())
+}
+
+// BeanNamed returns the bean of the name, it returns false when the bean is not a T.
+func BeanNamed[T any](name string) (T, bool) {
+	return inject.Named[T](DefaultApplication().Container(), name)
+}
+
+// MustBean returns the bean registered or provided as T, it panics when the bean is not found.
+func MustBean[T any]() T {
+	bean, ok := Bean[T]()
+	if !ok {
+		panic(fmt.Errorf("typed object %s not found", inject.TypeOf[T]()))
+	}
+	return bean
+}
+
+func Provide(constructor interface{}, options ...inject.ProvideOption) {
+	Default()
+	ctx.Provide(constructor, options...)
+}
+
 func Register(registers ...interfaces.InjectRegister) {
 	Default()
 	ctx.Register(registers...)
@@ -142,6 +180,11 @@ func Shutdown(shutdown ...interfaces.ShutdownHook) {
 	ctx.Shutdown(shutdown...)
 }
 
+func PostProcess(processors ...inject.BeanPostProcessor) {
+	Default()
+	ctx.PostProcess(processors...)
+}
+
 func Forward(c *gin.Context, path string) {
 	Default()
 	ctx.Forward(c, path)
@@ -29,6 +29,7 @@ import (
 	"github.com/go-zookeeper/zk"
 	"github.com/stella-go/siu"
 	"github.com/stella-go/siu/config"
+	"github.com/stella-go/siu/inject"
 )
 
 type S struct {
@@ -117,6 +118,42 @@ func (*Router) Router() map[string]gin.HandlerFunc {
 	}
 }
 
+type Store struct{}
+
+type Audit struct {
+	Store *Store
+}
+
+type StoreFactory struct{}
+
+func (*StoreFactory) Order() int {
+	return 0
+}
+
+func (*StoreFactory) Condition() bool {
+	return true
+}
+
+func (*StoreFactory) OnStart() error {
+	return nil
+}
+
+func (*StoreFactory) OnStop() error {
+	return nil
+}
+
+func (*StoreFactory) Name() string {
+	return "StoreFactory"
+}
+
+func (*StoreFactory) Named() map[string]interface{} {
+	return nil
+}
+
+func (*StoreFactory) Typed() map[reflect.Type]interface{} {
+	return map[reflect.Type]interface{}{inject.TypeOf[*Store](): &Store{}}
+}
+
 func TestRun(t *testing.T) {
 	go func() {
 		listener, _ := net.Listen("tcp", "127.0.0.1:2181")
@@ -136,10 +173,15 @@ func TestRun(t *testing.T) {
 	os.Setenv("STELLA_LOGGER_PATTERN", "%d{2006-01-02T15:04:05} %c %p [%g] - %l{7} %m")
 	os.Setenv("STELLA_LOGGER_SYSLOG", "127.0.0.1:514")
 	os.Setenv("STELLA_ZOOKEEPER", "zookeeperxxx")
-	os.Setenv("STELLA_ZOOKEEPER_SERVERS", "127.x0x.0.1:x21x81")
+	os.Setenv("STELLA_ZOOKEEPER_SERVERS", "ENC(127.x0x.0.1:x21x81)")
 	os.Setenv("STELLA_MIDDLEWARE_CROS_DISABLE", "true")
 	siu.Register(&R2{}, &R1{})
 	siu.Use(&S{})
 	siu.Route(&Router{})
+	siu.AutoFactory(&StoreFactory{})
+	siu.Provide(func(store *Store) *Audit { return &Audit{Store: store} }, inject.WithConditions(inject.OnBean(inject.TypeOf[*Store]())))
 	siu.Run()
+	if audit, ok := siu.Bean[*Audit](); !ok || audit.Store == nil {
+		t.Fatal("provider conditional on the bean of an auto factory", audit)
+	}
 }
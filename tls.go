@@ -0,0 +1,262 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package siu
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stella-go/siu/common"
+	"github.com/stella-go/siu/config"
+)
+
+const (
+	TLSKey               = "server.tls"
+	TLSCertFileKey       = TLSKey + ".cert-file"
+	TLSKeyFileKey        = TLSKey + ".key-file"
+	TLSMinVersionKey     = TLSKey + ".min-version"
+	TLSCiphersKey        = TLSKey + ".ciphers"
+	TLSClientCAFileKey   = TLSKey + ".client-ca-file"
+	TLSClientAuthKey     = TLSKey + ".client-auth"
+	TLSReloadIntervalKey = TLSKey + ".reload-interval"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var tlsClientAuths = map[string]tls.ClientAuthType{
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify-if-given":    tls.VerifyClientCertIfGiven,
+	"require-and-verify": tls.RequireAndVerifyClientCert,
+}
+
+// certReloader serves the certificate and client CA bundle from disk and reloads them when the files change.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	rwLock  *sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime map[string]time.Time
+}
+
+func newCertReloader(certFile string, keyFile string, caFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, caFile: caFile, rwLock: &sync.RWMutex{}, modTime: make(map[string]time.Time)}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.caFile != "" {
+		files = append(files, r.caFile)
+	}
+	return files
+}
+
+func (r *certReloader) load() error {
+	modTime := make(map[string]time.Time)
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		modTime[f] = info.ModTime()
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		bts, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bts) {
+			return fmt.Errorf("no certificate found in client CA file %s", r.caFile)
+		}
+	}
+	r.rwLock.Lock()
+	defer r.rwLock.Unlock()
+	r.cert = &cert
+	r.pool = pool
+	r.modTime = modTime
+	return nil
+}
+
+func (r *certReloader) changed() bool {
+	r.rwLock.RLock()
+	defer r.rwLock.RUnlock()
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().Equal(r.modTime[f]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *certReloader) watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.load(); err != nil {
+				common.ERROR("Failed to reload TLS certificate, keep using the previous one, with error", err)
+			} else {
+				common.INFO("TLS certificate reloaded")
+			}
+		}
+	}
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.rwLock.RLock()
+	defer r.rwLock.RUnlock()
+	return r.cert, nil
+}
+
+func (r *certReloader) clientCAs() *x509.CertPool {
+	r.rwLock.RLock()
+	defer r.rwLock.RUnlock()
+	return r.pool
+}
+
+func newTLSConfig(environment config.TypedConfig) (*tls.Config, *certReloader, error) {
+	certFile, ok1 := environment.GetString(TLSCertFileKey)
+	keyFile, ok2 := environment.GetString(TLSKeyFileKey)
+	if !ok1 && !ok2 {
+		return nil, nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, nil, fmt.Errorf("tls cert-file/key-file can not be empty")
+	}
+	caFile := environment.GetStringOr(TLSClientCAFileKey, "")
+	reloader, err := newCertReloader(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// an unquoted YAML 1.3 is a number
+	minVersion := environment.GetOr(TLSMinVersionKey, "1.2")
+	switch v := minVersion.(type) {
+	case float64:
+		minVersion = strconv.FormatFloat(v, 'f', 1, 64)
+	case int:
+		minVersion = fmt.Sprintf("%d.0", v)
+	}
+	version, ok := tlsVersions[fmt.Sprint(minVersion)]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported tls min-version %v", minVersion)
+	}
+
+	defaultClientAuth := "none"
+	if caFile != "" {
+		defaultClientAuth = "require-and-verify"
+	}
+	clientAuthName := environment.GetStringOr(TLSClientAuthKey, defaultClientAuth)
+	clientAuth, ok := tlsClientAuths[clientAuthName]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported tls client-auth %s", clientAuthName)
+	}
+	if clientAuth >= tls.VerifyClientCertIfGiven && caFile == "" {
+		return nil, nil, fmt.Errorf("tls client-auth %s requires client-ca-file", clientAuthName)
+	}
+
+	ciphers, _ := environment.Get(TLSCiphersKey)
+	cipherSuites, err := parseCipherSuites(ciphers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     version,
+		CipherSuites:   cipherSuites,
+		ClientAuth:     clientAuth,
+		GetCertificate: reloader.GetCertificate,
+	}
+	if caFile != "" {
+		tlsConfig.ClientCAs = reloader.clientCAs()
+		tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cfg := tlsConfig.Clone()
+			cfg.GetConfigForClient = nil
+			cfg.ClientCAs = reloader.clientCAs()
+			return cfg, nil
+		}
+	}
+	return tlsConfig, reloader, nil
+}
+
+func parseCipherSuites(ciphers interface{}) ([]uint16, error) {
+	names := make([]string, 0)
+	switch ciphers := ciphers.(type) {
+	case string:
+		names = strings.Split(ciphers, ",")
+	case []interface{}:
+		for _, c := range ciphers {
+			names = append(names, fmt.Sprintf("%v", c))
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	suites := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		suites[s.Name] = s.ID
+	}
+	for _, s := range tls.InsecureCipherSuites() {
+		suites[s.Name] = s.ID
+	}
+	ids := make([]uint16, 0)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := suites[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls cipher suite %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
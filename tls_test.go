@@ -0,0 +1,200 @@
+// Copyright 2010-2025 the original author or authors.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// 	http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package siu
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stella-go/siu/config"
+)
+
+// mapConfig is a configuration of flat keys.
+type mapConfig map[string]interface{}
+
+func (m mapConfig) Get(key string) (interface{}, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+func (m mapConfig) GetOr(key string, defaultValue interface{}) interface{} {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	return defaultValue
+}
+
+func (m mapConfig) Dump() []*config.Property {
+	properties := make([]*config.Property, 0, len(m))
+	for k, v := range m {
+		properties = append(properties, &config.Property{Key: k, Value: v, Source: "map"})
+	}
+	return properties
+}
+
+func typedConfig(m map[string]interface{}) config.TypedConfig {
+	return &config.DecryptEnvironment{Config: mapConfig(m)}
+}
+
+// writeCert writes a self-signed certificate and its key, the certificate is a CA bundle as well.
+func writeCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir)
+	emptyCA := filepath.Join(dir, "empty.pem")
+	os.WriteFile(emptyCA, []byte("no certificate"), 0600)
+	files := map[string]interface{}{TLSCertFileKey: certFile, TLSKeyFileKey: keyFile}
+	with := func(kv ...interface{}) map[string]interface{} {
+		m := make(map[string]interface{})
+		for k, v := range files {
+			m[k] = v
+		}
+		for i := 0; i < len(kv); i += 2 {
+			m[kv[i].(string)] = kv[i+1]
+		}
+		return m
+	}
+	tests := []struct {
+		name       string
+		conf       map[string]interface{}
+		disabled   bool
+		minVersion uint16
+		clientAuth tls.ClientAuthType
+		ciphers    int
+		err        string
+	}{
+		{name: "disabled", conf: map[string]interface{}{}, disabled: true},
+		{name: "missing key", conf: map[string]interface{}{TLSCertFileKey: certFile}, err: "can not be empty"},
+		{name: "missing file", conf: map[string]interface{}{TLSCertFileKey: certFile, TLSKeyFileKey: filepath.Join(dir, "none.pem")}, err: "no such file"},
+		{name: "defaults", conf: with(), minVersion: tls.VersionTLS12, clientAuth: tls.NoClientCert},
+		{name: "min version", conf: with(TLSMinVersionKey, "1.3"), minVersion: tls.VersionTLS13, clientAuth: tls.NoClientCert},
+		{name: "unsupported min version", conf: with(TLSMinVersionKey, "1.4"), err: "unsupported tls min-version 1.4"},
+		{name: "unquoted min version", conf: with(TLSMinVersionKey, 1.3), minVersion: tls.VersionTLS13, clientAuth: tls.NoClientCert},
+		{name: "unquoted min version 1.0", conf: with(TLSMinVersionKey, 1.0), minVersion: tls.VersionTLS10, clientAuth: tls.NoClientCert},
+		{name: "unquoted unsupported min version", conf: with(TLSMinVersionKey, 1.4), err: "unsupported tls min-version 1.4"},
+		{name: "unreadable min version", conf: with(TLSMinVersionKey, true), err: "unsupported tls min-version true"},
+		{name: "client ca", conf: with(TLSClientCAFileKey, certFile), minVersion: tls.VersionTLS12, clientAuth: tls.RequireAndVerifyClientCert},
+		{name: "client ca optional", conf: with(TLSClientCAFileKey, certFile, TLSClientAuthKey, "verify-if-given"), minVersion: tls.VersionTLS12, clientAuth: tls.VerifyClientCertIfGiven},
+		{name: "client ca without certificate", conf: with(TLSClientCAFileKey, emptyCA), err: "no certificate found"},
+		{name: "request without ca", conf: with(TLSClientAuthKey, "request"), minVersion: tls.VersionTLS12, clientAuth: tls.RequestClientCert},
+		{name: "verify without ca", conf: with(TLSClientAuthKey, "require-and-verify"), err: "requires client-ca-file"},
+		{name: "unsupported client auth", conf: with(TLSClientAuthKey, "always"), err: "unsupported tls client-auth always"},
+		{name: "ciphers", conf: with(TLSCiphersKey, "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"), minVersion: tls.VersionTLS12, clientAuth: tls.NoClientCert, ciphers: 1},
+		{name: "unsupported cipher", conf: with(TLSCiphersKey, "TLS_NONE"), err: "unsupported tls cipher suite TLS_NONE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, reloader, err := newTLSConfig(typedConfig(tt.conf))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.disabled {
+				if tlsConfig != nil || reloader != nil {
+					t.Fatal(tlsConfig)
+				}
+				return
+			}
+			if tlsConfig.MinVersion != tt.minVersion || tlsConfig.ClientAuth != tt.clientAuth || len(tlsConfig.CipherSuites) != tt.ciphers {
+				t.Fatal(tlsConfig.MinVersion, tlsConfig.ClientAuth, tlsConfig.CipherSuites)
+			}
+			if (tlsConfig.ClientCAs != nil) != (tt.conf[TLSClientCAFileKey] != nil) {
+				t.Fatal(tlsConfig.ClientCAs)
+			}
+			if cert, err := tlsConfig.GetCertificate(nil); err != nil || cert == nil {
+				t.Fatal(cert, err)
+			}
+		})
+	}
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	tests := []struct {
+		name    string
+		ciphers interface{}
+		ids     []uint16
+		err     string
+	}{
+		{"unset", nil, nil, ""},
+		{"string", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, ""},
+		{"list", []interface{}{"TLS_AES_128_GCM_SHA256"}, []uint16{tls.TLS_AES_128_GCM_SHA256}, ""},
+		{"insecure", "TLS_RSA_WITH_RC4_128_SHA", []uint16{tls.TLS_RSA_WITH_RC4_128_SHA}, ""},
+		{"empty names", "TLS_AES_128_GCM_SHA256,,", []uint16{tls.TLS_AES_128_GCM_SHA256}, ""},
+		{"unsupported", "TLS_AES_128_GCM_SHA256,TLS_UNKNOWN", nil, "unsupported tls cipher suite TLS_UNKNOWN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := parseCipherSuites(tt.ciphers)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err != nil || len(ids) != len(tt.ids) {
+				t.Fatal(ids, err)
+			}
+			for i := range ids {
+				if ids[i] != tt.ids[i] {
+					t.Fatal(ids)
+				}
+			}
+		})
+	}
+}